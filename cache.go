@@ -0,0 +1,118 @@
+package jsonpath
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultQueryCacheSize is the number of parsed queries the package-level
+// helpers keep by default
+const DefaultQueryCacheSize = 512
+
+// CacheStats reports the use of the query cache
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Len is the number of cached queries
+	Len int
+	// Size is the maximum number of cached queries, zero when the cache is off
+	Size int
+}
+
+// queryCache is a least recently used cache of compiled paths keyed by their
+// source text
+type queryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     list.List // of *Path, most recently used first
+	hits    uint64
+	misses  uint64
+}
+
+var defaultQueryCache = newQueryCache(DefaultQueryCacheSize)
+
+func newQueryCache(size int) *queryCache {
+	return &queryCache{size: size, entries: make(map[string]*list.Element)}
+}
+
+// SetQueryCacheSize sets how many parsed queries the package-level helpers
+// such as Get and GetMany keep, evicting the least recently used ones beyond
+// that. A size of zero or less turns the cache off and empties it.
+func SetQueryCacheSize(size int) {
+	defaultQueryCache.resize(size)
+}
+
+// QueryCacheStats returns the statistics of the cache used by the
+// package-level helpers
+func QueryCacheStats() CacheStats {
+	return defaultQueryCache.stats()
+}
+
+// compileCached is like Compile but reuses the paths compiled before. Invalid
+// paths are not cached.
+func compileCached(path string) (*Path, error) {
+	return defaultQueryCache.compile(path)
+}
+
+func (c *queryCache) compile(path string) (*Path, error) {
+	c.mu.Lock()
+	if c.size <= 0 {
+		c.mu.Unlock()
+		return Compile(path)
+	}
+	if elem, ok := c.entries[path]; ok {
+		c.hits++
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+		return elem.Value.(*Path), nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Parse without holding the lock; a concurrent miss on the same path
+	// may parse it twice and keep either result
+	p, err := Compile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return p, nil
+	}
+	if elem, ok := c.entries[path]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*Path), nil
+	}
+	c.entries[path] = c.lru.PushFront(p)
+	c.evict()
+	return p, nil
+}
+
+func (c *queryCache) resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size = size
+	c.evict()
+}
+
+// evict drops the least recently used paths beyond the size of the cache
+func (c *queryCache) evict() {
+	for c.lru.Len() > 0 && c.lru.Len() > c.size {
+		elem := c.lru.Back()
+		c.lru.Remove(elem)
+		delete(c.entries, elem.Value.(*Path).path)
+	}
+}
+
+func (c *queryCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := CacheStats{Hits: c.hits, Misses: c.misses, Len: c.lru.Len()}
+	if c.size > 0 {
+		s.Size = c.size
+	}
+	return s
+}
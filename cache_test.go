@@ -0,0 +1,105 @@
+package jsonpath
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestQueryCache(t *testing.T) {
+	c := newQueryCache(2)
+
+	a, err := c.compile("$.a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.compile("$.a"); again != a {
+		t.Error("compile() did not reuse the cached path")
+	}
+	c.compile("$.b")
+	c.compile("$.a") // $.b is now the least recently used
+	c.compile("$.c")
+	if _, ok := c.entries["$.b"]; ok {
+		t.Error("compile() kept $.b beyond the size of the cache")
+	}
+	if _, err := c.compile("$["); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("compile() error = %v, want ErrInvalidPath", err)
+	}
+
+	want := CacheStats{Hits: 2, Misses: 4, Len: 2, Size: 2}
+	if got := c.stats(); got != want {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+
+	c.resize(0)
+	if p, _ := c.compile("$.a"); p == a {
+		t.Error("compile() used the cache after it was turned off")
+	}
+	want = CacheStats{Hits: 2, Misses: 4}
+	if got := c.stats(); got != want {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestQueryCache_Concurrent(t *testing.T) {
+	c := newQueryCache(4)
+	paths := []string{"$.a", "$.b", "$.c", "$.d", "$.e", "$[0]"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				path := paths[(i+j)%len(paths)]
+				if p, err := c.compile(path); err != nil || p.String() != path {
+					t.Errorf("compile(%q) = %v, %v", path, p, err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if s := c.stats(); s.Len > 4 || s.Hits+s.Misses != 800 {
+		t.Errorf("stats() = %+v, want at most 4 entries and 800 lookups", s)
+	}
+}
+
+func TestSetQueryCacheSize(t *testing.T) {
+	defer SetQueryCacheSize(DefaultQueryCacheSize)
+
+	SetQueryCacheSize(DefaultQueryCacheSize)
+	before := QueryCacheStats()
+	Get(`{"a": 1}`, "$.cache_test")
+	Get(`{"a": 1}`, "$.cache_test")
+	after := QueryCacheStats()
+	if after.Hits-before.Hits != 1 || after.Size != DefaultQueryCacheSize {
+		t.Errorf("QueryCacheStats() = %+v after %+v, want one more hit", after, before)
+	}
+
+	SetQueryCacheSize(0)
+	if s := QueryCacheStats(); s.Len != 0 || s.Size != 0 {
+		t.Errorf("QueryCacheStats() = %+v, want an empty cache turned off", s)
+	}
+	if r := Get(`{"a": 1}`, "$.a"); r.Int() != 1 {
+		t.Errorf("Get() = %v without a cache, want 1", r)
+	}
+}
+
+func BenchmarkGetCached(b *testing.B) {
+	json := `{"store": {"book": [{"title": "A", "price": 8.95}]}}`
+	path := "$.store.book[?@.price < 10].title"
+
+	b.Run("Cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Get(json, path)
+		}
+	})
+	b.Run("Uncached", func(b *testing.B) {
+		SetQueryCacheSize(0)
+		defer SetQueryCacheSize(DefaultQueryCacheSize)
+		for i := 0; i < b.N; i++ {
+			Get(json, path)
+		}
+	})
+}
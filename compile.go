@@ -0,0 +1,320 @@
+package jsonpath
+
+// The evaluator does not interpret the AST. NewEvaluator binds every segment,
+// selector and filter expression to a closure once, so evaluating a filter
+// against a candidate only calls the closures built for it: literals are
+// already parsed and functions already looked up in functionRegistry.
+
+// selectorFunc calls emit for every node it selects from v and returns false
+// if emit stopped the selection
+type selectorFunc func(e *evaluation, v Value, emit selectFunc) bool
+
+// filterFunc reports whether the current node passes a filter expression
+type filterFunc func(e *evaluation, current Value) bool
+
+// comparableFunc returns the value of one side of a comparison, or nil for Nothing
+type comparableFunc func(e *evaluation, current Value) Value
+
+// queryFunc returns the nodes selected by a filter query
+type queryFunc func(e *evaluation, current Value) []Value
+
+// callFunc returns the result of a function call or function argument
+type callFunc func(e *evaluation, current Value) (interface{}, error)
+
+// compiledSegment is a segment of a query bound to closures
+type compiledSegment struct {
+	descendant bool
+	selectors  []selectorFunc
+	// filters holds the filter of each filter selector at the position of the
+	// selector, nil for the other selectors
+	filters []filterFunc
+}
+
+func compileSegments(segments []*Segment) []compiledSegment {
+	compiled := make([]compiledSegment, len(segments))
+	for i, seg := range segments {
+		c := compiledSegment{
+			descendant: seg.Type == DescendantSegment,
+			selectors:  make([]selectorFunc, len(seg.Selectors)),
+			filters:    make([]filterFunc, len(seg.Selectors)),
+		}
+		for j, sel := range seg.Selectors {
+			if sel.Type == FilterSelector {
+				c.filters[j] = compileFilter(sel.Filter)
+			}
+			c.selectors[j] = compileSelector(sel, c.filters[j])
+		}
+		compiled[i] = c
+	}
+	return compiled
+}
+
+// compileSelector binds a selector, using filter for filter selectors
+func compileSelector(sel *Selector, filter filterFunc) selectorFunc {
+	switch sel.Type {
+	case NameSelector:
+		name := sel.Name
+		return func(e *evaluation, v Value, emit selectFunc) bool {
+			return e.evalNameSelector(v, name, emit)
+		}
+	case WildcardSelector:
+		return func(e *evaluation, v Value, emit selectFunc) bool {
+			return e.evalWildcardSelector(v, emit)
+		}
+	case IndexSelector:
+		index := sel.Index
+		return func(e *evaluation, v Value, emit selectFunc) bool {
+			return e.evalIndexSelector(v, index, emit)
+		}
+	case SliceSelector:
+		slice := sel.Slice
+		return func(e *evaluation, v Value, emit selectFunc) bool {
+			return e.evalSliceSelector(v, slice, emit)
+		}
+	case FilterSelector:
+		return func(e *evaluation, v Value, emit selectFunc) bool {
+			return e.evalFilterSelector(v, filter, emit)
+		}
+	}
+	return func(*evaluation, Value, selectFunc) bool { return true }
+}
+
+func compileFilter(expr *FilterExpr) filterFunc {
+	switch expr.Type {
+	case FilterLogicalOr:
+		left, right := compileFilter(expr.Left), compileFilter(expr.Right)
+		return func(e *evaluation, current Value) bool {
+			return left(e, current) || right(e, current)
+		}
+	case FilterLogicalAnd:
+		left, right := compileFilter(expr.Left), compileFilter(expr.Right)
+		return func(e *evaluation, current Value) bool {
+			return left(e, current) && right(e, current)
+		}
+	case FilterLogicalNot:
+		operand := compileFilter(expr.Operand)
+		return func(e *evaluation, current Value) bool {
+			return !operand(e, current)
+		}
+	case FilterParen:
+		return compileFilter(expr.Operand)
+	case FilterComparison:
+		return compileComparison(expr.Comp)
+	case FilterTest:
+		return compileTest(expr.Test)
+	}
+	return func(*evaluation, Value) bool { return false }
+}
+
+// compileComparison compares two comparables as defined by RFC 9535 §2.3.5.2.2.
+// An empty result (Nothing) is only equal to another empty result.
+func compileComparison(comp *Comparison) filterFunc {
+	left, right := compileComparable(comp.Left), compileComparable(comp.Right)
+
+	switch comp.Op {
+	case CompEq:
+		return func(e *evaluation, current Value) bool {
+			return e.valuesEqual(left(e, current), right(e, current), 0)
+		}
+	case CompNe:
+		return func(e *evaluation, current Value) bool {
+			return !e.valuesEqual(left(e, current), right(e, current), 0)
+		}
+	case CompLt:
+		return func(e *evaluation, current Value) bool {
+			return valueLess(left(e, current), right(e, current))
+		}
+	case CompLe:
+		return func(e *evaluation, current Value) bool {
+			l, r := left(e, current), right(e, current)
+			return valueLess(l, r) || e.valuesEqual(l, r, 0)
+		}
+	case CompGt:
+		return func(e *evaluation, current Value) bool {
+			l, r := left(e, current), right(e, current)
+			return valueLess(r, l)
+		}
+	case CompGe:
+		return func(e *evaluation, current Value) bool {
+			l, r := left(e, current), right(e, current)
+			return valueLess(r, l) || e.valuesEqual(l, r, 0)
+		}
+	}
+	return func(*evaluation, Value) bool { return false }
+}
+
+func compileComparable(c *Comparable) comparableFunc {
+	switch c.Type {
+	case ComparableLiteral:
+		var lit Value = jsonValue{r: literalResult(c.Literal)}
+		return func(*evaluation, Value) Value { return lit }
+	case ComparableSingularQuery:
+		return compileSingularQuery(c.SingularQuery)
+	case ComparableFuncExpr:
+		name := c.FuncExpr.Name
+		call := compileFuncCall(c.FuncExpr)
+		return func(e *evaluation, current Value) Value {
+			result, err := call(e, current)
+			if err != nil {
+				e.fail(&FunctionError{Name: name, Err: err})
+				return nil
+			}
+			return newJSONValue(result.(Result))
+		}
+	}
+	return func(*evaluation, Value) Value { return nil }
+}
+
+func compileSingularQuery(query *SingularQuery) comparableFunc {
+	relative := query.Relative
+	steps := make([]func(v Value) (Value, bool), len(query.Segments))
+	for i, seg := range query.Segments {
+		switch seg.Type {
+		case SingularNameSegment:
+			name := seg.Name
+			steps[i] = func(v Value) (Value, bool) {
+				if v.Kind() != KindObject {
+					return nil, false
+				}
+				return v.Member(name)
+			}
+		case SingularIndexSegment:
+			index := seg.Index
+			steps[i] = func(v Value) (Value, bool) {
+				if v.Kind() != KindArray {
+					return nil, false
+				}
+				i := index
+				if i < 0 {
+					i += v.Len()
+				}
+				if i < 0 {
+					return nil, false
+				}
+				return v.Index(i)
+			}
+		}
+	}
+
+	eval := func(v Value) Value {
+		for _, step := range steps {
+			next, ok := step(v)
+			if !ok {
+				return nil
+			}
+			v = next
+		}
+		return v
+	}
+
+	if relative {
+		return func(e *evaluation, current Value) Value {
+			return eval(current)
+		}
+	}
+	// An absolute query selects the same node for every candidate
+	return func(e *evaluation, _ Value) Value {
+		if v, ok := e.absoluteResult(query); ok {
+			v, _ := v.(Value)
+			return v
+		}
+		v := eval(e.root)
+		e.setAbsoluteResult(query, v)
+		return v
+	}
+}
+
+func compileTest(test *TestExpr) filterFunc {
+	if sq := asSingularQuery(test.FilterQuery); sq != nil {
+		query := compileSingularQuery(sq)
+		return func(e *evaluation, current Value) bool {
+			return query(e, current) != nil
+		}
+	}
+	if test.FilterQuery != nil {
+		query := compileFilterQuery(test.FilterQuery)
+		return func(e *evaluation, current Value) bool {
+			return len(query(e, current)) > 0
+		}
+	}
+	if test.FuncExpr != nil {
+		name := test.FuncExpr.Name
+		call := compileFuncCall(test.FuncExpr)
+		return func(e *evaluation, current Value) bool {
+			result, err := call(e, current)
+			if err != nil {
+				e.fail(&FunctionError{Name: name, Err: err})
+				return false
+			}
+			if logical, ok := result.(bool); ok {
+				return logical
+			}
+			if nodes, ok := result.([]Result); ok {
+				return len(nodes) > 0
+			}
+			return false
+		}
+	}
+	return func(*evaluation, Value) bool { return false }
+}
+
+func compileFilterQuery(fq *FilterQuery) queryFunc {
+	relative := fq.Relative
+	segments := compileSegments(fq.Segments)
+
+	eval := func(e *evaluation, current Value) []Value {
+		values := []Value{current}
+		for _, seg := range segments {
+			var selected []Value
+			for _, v := range values {
+				if seg.descendant {
+					selected = append(selected, e.descendantsAll(v, seg.selectors)...)
+				} else {
+					selected = append(selected, e.selectAll(v, seg.selectors)...)
+				}
+			}
+			values = selected
+			if len(values) == 0 {
+				return nil
+			}
+		}
+		return values
+	}
+
+	if relative {
+		return eval
+	}
+	// An absolute query selects the same nodes for every candidate
+	return func(e *evaluation, _ Value) []Value {
+		if values, ok := e.absoluteResult(fq); ok {
+			return values.([]Value)
+		}
+		values := eval(e, e.root)
+		e.setAbsoluteResult(fq, values)
+		return values
+	}
+}
+
+// asSingularQuery returns fq as a singular query when all of its segments
+// select a single member or element, so it can be evaluated without
+// collecting nodelists. It returns nil otherwise.
+func asSingularQuery(fq *FilterQuery) *SingularQuery {
+	if fq == nil {
+		return nil
+	}
+	sq := &SingularQuery{Relative: fq.Relative}
+	for _, seg := range fq.Segments {
+		if seg.Type != ChildSegment || len(seg.Selectors) != 1 {
+			return nil
+		}
+		switch sel := seg.Selectors[0]; sel.Type {
+		case NameSelector:
+			sq.Segments = append(sq.Segments, &SingularSegment{Type: SingularNameSegment, Name: sel.Name})
+		case IndexSelector:
+			sq.Segments = append(sq.Segments, &SingularSegment{Type: SingularIndexSegment, Index: sel.Index})
+		default:
+			return nil
+		}
+	}
+	return sq
+}
@@ -0,0 +1,63 @@
+package jsonpath
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+)
+
+// Unmarshal decodes the result into v with encoding/json, so a sub-tree can be
+// read straight into a typed struct. It returns ErrNoMatch if the result does
+// not exist.
+func (r Result) Unmarshal(v interface{}) error {
+	if !r.Exists() {
+		return ErrNoMatch
+	}
+	return stdjson.Unmarshal([]byte(encodeResult(r)), v)
+}
+
+// GetInto executes a JSONPath query and decodes the first result into v.
+//
+// It returns ErrNoMatch if the query selects nothing, and reports invalid
+// paths, malformed JSON and function errors as QueryOne does.
+func GetInto(json, path string, v interface{}) error {
+	p, err := compileCached(path)
+	if err != nil {
+		return err
+	}
+	return p.GetInto(json, v)
+}
+
+// GetInto evaluates the path against json and decodes the first result into v, see GetInto
+func (p *Path) GetInto(json string, v interface{}) error {
+	r, err := p.QueryOne(json)
+	if err != nil {
+		return err
+	}
+	if !r.Exists() {
+		return fmt.Errorf("%w: %s", ErrNoMatch, p.path)
+	}
+	return r.Unmarshal(v)
+}
+
+// PlainValue is like Value, but returns objects and arrays recursively as
+// map[string]interface{} and []interface{} instead of map[string]Result and
+// []Result. Numbers are float64 as in Value.
+func (r Result) PlainValue() interface{} {
+	switch {
+	case r.IsArray():
+		elems := r.Array()
+		arr := make([]interface{}, len(elems))
+		for i, elem := range elems {
+			arr[i] = elem.PlainValue()
+		}
+		return arr
+	case r.IsObject():
+		members := r.MapKVList()
+		obj := make(map[string]interface{}, len(members))
+		for _, kv := range members {
+			obj[kv.Key] = kv.Value.PlainValue()
+		}
+		return obj
+	}
+	return r.Value()
+}
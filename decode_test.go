@@ -0,0 +1,79 @@
+package jsonpath
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type decodeTestBook struct {
+	Title string   `json:"title"`
+	Price float64  `json:"price"`
+	Tags  []string `json:"tags"`
+}
+
+func TestResult_Unmarshal(t *testing.T) {
+	json := `{"book": {"title": "Moby Dick", "price": 8.99, "tags": ["sea", "whale"]}, "n": 3, "s": "xé"}`
+
+	var book decodeTestBook
+	if err := Get(json, "$.book").Unmarshal(&book); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := decodeTestBook{Title: "Moby Dick", Price: 8.99, Tags: []string{"sea", "whale"}}
+	if !reflect.DeepEqual(book, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", book, want)
+	}
+
+	var n int
+	if err := Get(json, "$.n").Unmarshal(&n); err != nil || n != 3 {
+		t.Errorf("Unmarshal() = %d, %v, want 3", n, err)
+	}
+
+	var s string
+	if err := Get(json, "$.s").Unmarshal(&s); err != nil || s != "xé" {
+		t.Errorf("Unmarshal() = %q, %v, want %q", s, err, "xé")
+	}
+
+	if err := Get(json, "$.missing").Unmarshal(&s); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Unmarshal() error = %v, want ErrNoMatch", err)
+	}
+
+	if err := Get(json, "$.book.title").Unmarshal(&n); err == nil {
+		t.Error("Unmarshal() of string into int returned no error")
+	}
+}
+
+func TestGetInto(t *testing.T) {
+	json := `{"books": [{"title": "A", "price": 1}, {"title": "B", "price": 2}]}`
+
+	var books []decodeTestBook
+	if err := GetInto(json, "$.books", &books); err != nil || len(books) != 2 || books[1].Title != "B" {
+		t.Errorf("GetInto() = %+v, %v", books, err)
+	}
+
+	var book decodeTestBook
+	if err := GetInto(json, "$.books[?@.price > 1]", &book); err != nil || book.Title != "B" {
+		t.Errorf("GetInto() = %+v, %v", book, err)
+	}
+
+	if err := GetInto(json, "$.none", &book); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("GetInto() error = %v, want ErrNoMatch", err)
+	}
+	if err := GetInto(json, "$[", &book); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("GetInto() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestResult_PlainValue(t *testing.T) {
+	r := parseValue(`{"a": [1, "x", null, true, {"b": []}], "c": {}}`)
+	want := map[string]interface{}{
+		"a": []interface{}{1.0, "x", nil, true, map[string]interface{}{"b": []interface{}{}}},
+		"c": map[string]interface{}{},
+	}
+	if got := r.PlainValue(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PlainValue() = %#v, want %#v", got, want)
+	}
+	if got := parseValue(`"s"`).PlainValue(); got != "s" {
+		t.Errorf("PlainValue() = %#v, want %q", got, "s")
+	}
+}
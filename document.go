@@ -0,0 +1,183 @@
+package jsonpath
+
+// Document is a JSON document parsed once for running many queries against
+// it. ParseDocument indexes the offset of every value, so queries walk the
+// index instead of scanning the text again.
+//
+// A Document is read-only and safe for concurrent use.
+type Document struct {
+	nodes []docNode
+}
+
+// docNode is a value of the document. The nodes are stored in document order,
+// the root first.
+type docNode struct {
+	r Result
+	// key is the member name of the value when its parent is an object
+	key string
+	// children holds the nodes of the array elements or object members
+	children []int
+}
+
+// ParseDocument validates json and indexes its values
+func ParseDocument(json string) (*Document, error) {
+	if err := Validate(json); err != nil {
+		return nil, err
+	}
+	d := &Document{}
+	root := parseValue(json)
+	if root.Type == JSONTypeJSON {
+		d.add(json, skipWhitespaceJSON(json, 0))
+		d.nodes[0].r.Raw = root.Raw
+	} else {
+		d.nodes = append(d.nodes, docNode{r: root})
+	}
+	return d, nil
+}
+
+// ParseDocumentBytes is like ParseDocument with []byte input
+func ParseDocumentBytes(json []byte) (*Document, error) {
+	return ParseDocument(string(json))
+}
+
+// add indexes the valid value starting at i and returns its node and the
+// offset after it
+func (d *Document) add(json string, i int) (int, int) {
+	n := len(d.nodes)
+	d.nodes = append(d.nodes, docNode{})
+	if json[i] != '{' && json[i] != '[' {
+		r, end := parseArrayElement(json, i)
+		r.Index = i
+		d.nodes[n].r = r
+		return n, end
+	}
+
+	start, object := i, json[i] == '{'
+	var children []int
+	i = skipWhitespaceJSON(json, i+1)
+	for json[i] != '}' && json[i] != ']' {
+		var key string
+		if object {
+			keyEnd, escaped := scanStringJSON(json, i)
+			if escaped {
+				_, key = tostr(json[i:keyEnd])
+			} else {
+				key = json[i+1 : keyEnd-1]
+			}
+			i = skipWhitespaceJSON(json, keyEnd)
+			i = skipWhitespaceJSON(json, i+1) // ':'
+		}
+		child, end := d.add(json, i)
+		d.nodes[child].key = key
+		children = append(children, child)
+		i = skipWhitespaceJSON(json, end)
+		if json[i] == ',' {
+			i = skipWhitespaceJSON(json, i+1)
+		}
+	}
+	d.nodes[n].r = Result{Type: JSONTypeJSON, Raw: json[start : i+1], Index: start}
+	d.nodes[n].children = children
+	return n, i + 1
+}
+
+// Root returns the root value of the document
+func (d *Document) Root() Result {
+	return d.nodes[0].r
+}
+
+// Get executes a JSONPath query against the document and returns the first result
+func (d *Document) Get(path string) Result {
+	p, err := compileCached(path)
+	if err != nil {
+		return Result{}
+	}
+	var first Result
+	ev := newEvaluation(docValue{d: d}, &p.eval.opts)
+	ev.forEach(p.eval.segments, func(n node) bool {
+		first = valueResult(n.value)
+		return false
+	})
+	return first
+}
+
+// GetMany executes a JSONPath query against the document and returns all results
+func (d *Document) GetMany(path string) []Result {
+	p, err := compileCached(path)
+	if err != nil {
+		return nil
+	}
+	results, _ := d.Query(p)
+	return results
+}
+
+// Query evaluates a compiled path against the document and returns all
+// results. Errors are reported as by Path.QueryAll.
+func (d *Document) Query(p *Path) ([]Result, error) {
+	ev := newEvaluation(docValue{d: d}, &p.eval.opts)
+	nodes := ev.evaluate(p.eval.segments)
+	if len(nodes) == 0 {
+		return nil, ev.err
+	}
+	results := make([]Result, len(nodes))
+	for i, n := range nodes {
+		results[i] = valueResult(n.value)
+	}
+	return results, ev.err
+}
+
+// docValue is a Value backed by the index of a Document
+type docValue struct {
+	d *Document
+	i int
+}
+
+func (v docValue) node() *docNode {
+	return &v.d.nodes[v.i]
+}
+
+func (v docValue) Kind() Kind {
+	return jsonValue{r: v.node().r}.Kind()
+}
+
+func (v docValue) Member(name string) (Value, bool) {
+	if v.Kind() != KindObject {
+		return nil, false
+	}
+	// The last member wins, as with JSON text
+	children := v.node().children
+	for i := len(children) - 1; i >= 0; i-- {
+		if c := children[i]; v.d.nodes[c].key == name {
+			return docValue{d: v.d, i: c}, true
+		}
+	}
+	return nil, false
+}
+
+func (v docValue) Index(i int) (Value, bool) {
+	children := v.node().children
+	if v.Kind() != KindArray || i < 0 || i >= len(children) {
+		return nil, false
+	}
+	return docValue{d: v.d, i: children[i]}, true
+}
+
+func (v docValue) Len() int {
+	return len(v.node().children)
+}
+
+func (v docValue) Each(fn func(step LocationStep, v Value) bool) {
+	object := v.Kind() == KindObject
+	for i, c := range v.node().children {
+		step := indexStep(i)
+		if object {
+			step = nameStep(v.d.nodes[c].key)
+		}
+		if !fn(step, docValue{d: v.d, i: c}) {
+			return
+		}
+	}
+}
+
+func (v docValue) Bool() bool      { return v.node().r.Type == JSONTypeTrue }
+func (v docValue) Number() float64 { return v.node().r.Num }
+func (v docValue) Str() string     { return v.node().r.Str }
@@ -0,0 +1,123 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	json := ` {
+		"store": {
+			"book": [
+				{"title": "A", "price": 8.95, "tags": ["x", "y"]},
+				{"title": "B\"!", "price": 12.99},
+				{"title": "C", "price": 8.99, "isbn": "0-553"}
+			],
+			"bicycle": {"color": "red", "price": 399},
+			"abc": 1,
+			"dup": 1, "dup": 2
+		},
+		"max": 10
+	}`
+	doc, err := ParseDocument(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{
+		"$",
+		"$.store.bicycle.color",
+		"$.store.book[*].title",
+		"$.store.book[-1]",
+		"$.store.book[::-2].title",
+		"$..price",
+		"$..*",
+		"$.store.book[?@.price < $.max].title",
+		"$.store.book[?length(@.tags) == 2]",
+		"$.store.book[?match(@.title, 'B.*')]",
+		"$.store.abc",
+		"$.store.dup",
+		"$.nothing",
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			got := doc.GetMany(path)
+			want := GetMany(json, path)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Document.GetMany(%q) = %v, want %v", path, got, want)
+			}
+			if first := doc.Get(path); len(want) > 0 && first != want[0] {
+				t.Errorf("Document.Get(%q) = %v, want %v", path, first, want[0])
+			}
+		})
+	}
+
+	if r := doc.Get("$["); r.Exists() {
+		t.Errorf("Document.Get() with an invalid path = %v, want none", r)
+	}
+}
+
+func TestDocument_Scalar(t *testing.T) {
+	doc, err := ParseDocument(` "a" `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := doc.Root(); r.Str != "a" || r.Index != 1 {
+		t.Errorf("Root() = %v, want \"a\" at 1", r)
+	}
+	if got := doc.GetMany("$.*"); got != nil {
+		t.Errorf("GetMany() = %v, want none", got)
+	}
+}
+
+func TestDocument_Errors(t *testing.T) {
+	if _, err := ParseDocument(`{"a": [1, }`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("ParseDocument() error = %v, want ErrInvalidJSON", err)
+	}
+
+	doc, err := ParseDocument(`{"a": [1, 2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := MustCompile("$.a[?foo(@)]")
+	if _, err := doc.Query(p); !errors.Is(err, ErrFunction) {
+		t.Errorf("Query() error = %v, want ErrFunction", err)
+	}
+}
+
+func BenchmarkDocumentManyQueries(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`{"items": [`)
+	for i := 0; i < 200; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"id": %d, "name": "item %d", "tags": ["a", "b"], "price": %d}`, i, i, i%50)
+	}
+	sb.WriteString(`]}`)
+	json := sb.String()
+
+	var paths []*Path
+	for i := 0; i < 50; i++ {
+		paths = append(paths, MustCompile(fmt.Sprintf("$.items[%d].name", i*4)))
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, p := range paths {
+				p.GetMany(json)
+			}
+		}
+	})
+	b.Run("Document", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			doc, _ := ParseDocument(json)
+			for _, p := range paths {
+				doc.Query(p)
+			}
+		}
+	})
+}
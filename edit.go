@@ -0,0 +1,463 @@
+package jsonpath
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Errors reported by the editing functions
+var (
+	// ErrNotContainer is reported when an edit needs an object or array but the
+	// selected value is neither
+	ErrNotContainer = errors.New("jsonpath: target is not an object or array")
+	// ErrCannotCreate is reported when a missing node cannot be created
+	ErrCannotCreate = errors.New("jsonpath: cannot create missing node")
+)
+
+// SetOptions configures Set
+type SetOptions struct {
+	// CreateMissing creates missing object members along the path, nesting new
+	// objects as needed. It only applies to singular paths made of child name
+	// and index segments such as $.a.b[0].c; missing array elements are never
+	// created.
+	CreateMissing bool
+}
+
+// Set replaces the value of every node selected by path with value, encoded
+// with encoding/json, and returns the rewritten document.
+//
+// Every node selected by wildcards, filters and descendant segments is
+// replaced. When a selected node lies inside another selected node, only the
+// outer node is replaced. A query without matches returns json unchanged.
+func Set(json, path string, value interface{}) (string, error) {
+	return SetWithOptions(json, path, value, nil)
+}
+
+// SetBytes is like Set with []byte input and output
+func SetBytes(json []byte, path string, value interface{}) ([]byte, error) {
+	s, err := Set(string(json), path, value)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// SetRaw is like Set but value is raw JSON inserted as is
+func SetRaw(json, path, value string) (string, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return "", err
+	}
+	return p.SetRaw(json, value)
+}
+
+// SetWithOptions is like Set with options
+func SetWithOptions(json, path string, value interface{}, opts *SetOptions) (string, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return "", err
+	}
+	return p.SetWithOptions(json, value, opts)
+}
+
+// Set replaces the value of every node selected by the path, see Set
+func (p *Path) Set(json string, value interface{}) (string, error) {
+	return p.SetWithOptions(json, value, nil)
+}
+
+// SetRaw replaces the value of every node selected by the path with raw JSON
+func (p *Path) SetRaw(json, value string) (string, error) {
+	if !Valid(value) {
+		return "", fmt.Errorf("%w: invalid value %q", ErrInvalidJSON, value)
+	}
+	return p.set(json, value, nil)
+}
+
+// SetWithOptions replaces the value of every node selected by the path, see SetWithOptions
+func (p *Path) SetWithOptions(json string, value interface{}, opts *SetOptions) (string, error) {
+	raw, err := marshalValue(value)
+	if err != nil {
+		return "", err
+	}
+	return p.set(json, raw, opts)
+}
+
+func (p *Path) set(json, raw string, opts *SetOptions) (string, error) {
+	if opts != nil && opts.CreateMissing && isSingularPath(p.eval.query) {
+		return p.setCreate(json, raw)
+	}
+
+	nodes, err := p.eval.evaluateNodes(json)
+	if err != nil {
+		return "", err
+	}
+	edits := make([]edit, 0, len(nodes))
+	for _, n := range nodes {
+		start, end := valueSpan(n)
+		edits = append(edits, edit{start: start, end: end, text: raw})
+	}
+	return applyEdits(json, edits), nil
+}
+
+// setCreate sets the value of a singular path, creating missing object members
+func (p *Path) setCreate(json, raw string) (string, error) {
+	ev, err := p.eval.newEvaluation(json)
+	if err != nil {
+		return "", err
+	}
+
+	segments := p.eval.query.Segments
+	cur := Node{Value: valueResult(ev.root), Location: Location{}}
+	for k, seg := range segments {
+		var next []Node
+		p.eval.segments[k].selectors[0](ev, newJSONValue(cur.Value), func(step LocationStep, v Value) bool {
+			next = append(next, Node{Location: cur.Location.Child(step), Value: valueResult(v)})
+			return true
+		})
+		if len(next) > 0 {
+			cur = next[0]
+			continue
+		}
+
+		sel := seg.Selectors[0]
+		if sel.Type != NameSelector {
+			return "", fmt.Errorf("%w: array element %s", ErrCannotCreate, cur.Location.Child(indexStep(sel.Index)))
+		}
+		if !cur.Value.IsObject() {
+			return "", fmt.Errorf("%w: %s", ErrNotContainer, cur.Location)
+		}
+		children := childSpans(json, cur.Value)
+		l := containerLayout(json, cur, children)
+		// wrap the value in objects for the remaining segments
+		for j := len(segments) - 1; j > k; j-- {
+			s := segments[j].Selectors[0]
+			if s.Type != NameSelector {
+				return "", fmt.Errorf("%w: array element under %s", ErrCannotCreate, cur.Location.Child(nameStep(sel.Name)))
+			}
+			raw = "{" + l.member(s.Name, raw) + "}"
+		}
+		return applyEdits(json, []edit{appendChildEdit(json, cur, children, l, l.member(sel.Name, raw))}), nil
+	}
+
+	start, end := valueSpan(cur)
+	return applyEdits(json, []edit{{start: start, end: end, text: raw}}), nil
+}
+
+// isSingularPath reports whether the query only has child segments with a single name or index selector
+func isSingularPath(query *Query) bool {
+	for _, seg := range query.Segments {
+		if seg.Type != ChildSegment || len(seg.Selectors) != 1 {
+			return false
+		}
+		if t := seg.Selectors[0].Type; t != NameSelector && t != IndexSelector {
+			return false
+		}
+	}
+	return true
+}
+
+// edit replaces json[start:end] with text
+type edit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits applies edits to json. Edits nested in an earlier edit are
+// dropped, so replacing an outer node wins over its descendants.
+func applyEdits(json string, edits []edit) string {
+	if len(edits) == 0 {
+		return json
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].end > edits[j].end
+	})
+
+	var b strings.Builder
+	b.Grow(len(json))
+	pos := 0
+	for _, e := range edits {
+		if e.start < pos {
+			continue
+		}
+		b.WriteString(json[pos:e.start])
+		b.WriteString(e.text)
+		pos = e.end
+	}
+	b.WriteString(json[pos:])
+	return b.String()
+}
+
+// valueSpan returns the byte range of a node's value in the document
+func valueSpan(n Node) (start, end int) {
+	raw := n.Value.Raw
+	if len(n.Location) == 0 {
+		// the root of an object or array takes the rest of the document
+		raw = strings.TrimRight(raw, " \t\r\n")
+	}
+	return n.Value.Index, n.Value.Index + len(raw)
+}
+
+func isSpaceJSON(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
+}
+
+// marshalValue encodes v as JSON without escaping HTML characters
+func marshalValue(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	enc := stdjson.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// quoteString encodes s as a JSON string
+func quoteString(s string) string {
+	raw, _ := marshalValue(s)
+	return raw
+}
+
+// Delete removes every object member and array element selected by path and
+// returns the rewritten document and the number of nodes removed.
+//
+// Each node is removed once even if it is selected several times, and nodes
+// inside another removed node are not counted. All removals are applied to
+// the original text in a single pass, so array indices selected by the query
+// stay valid regardless of the order in which they were selected. The root
+// value is not a member or element and is never removed.
+func Delete(json, path string) (string, int, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return "", 0, err
+	}
+	return p.Delete(json)
+}
+
+// DeleteBytes is like Delete with []byte input and output
+func DeleteBytes(json []byte, path string) ([]byte, int, error) {
+	s, n, err := Delete(string(json), path)
+	if err != nil {
+		return nil, 0, err
+	}
+	return []byte(s), n, nil
+}
+
+// Delete removes every node selected by the path, see Delete
+func (p *Path) Delete(json string) (string, int, error) {
+	nodes, err := p.eval.evaluateNodes(json)
+	if err != nil {
+		return "", 0, err
+	}
+
+	targets := make(map[int]bool, len(nodes))
+	for _, n := range nodes {
+		if len(n.Location) > 0 {
+			targets[n.Value.Index] = true
+		}
+	}
+	edits, n := deletionEdits(json, targets)
+	return applyEdits(json, edits), n, nil
+}
+
+// deletionEdits returns the edits removing the members and elements whose
+// values start at one of the target offsets, and the number of them
+func deletionEdits(json string, targets map[int]bool) ([]edit, int) {
+	if len(targets) == 0 {
+		return nil, 0
+	}
+	d := deletion{json: json, targets: targets}
+	d.walk(parseValue(json))
+	return d.edits, d.count
+}
+
+// deletion collects the edits removing the children whose values start at one
+// of the target offsets
+type deletion struct {
+	json    string
+	targets map[int]bool
+	edits   []edit
+	count   int
+}
+
+func (d *deletion) walk(container Result) {
+	children := childSpans(d.json, container)
+	removed := make([]bool, len(children))
+	for i, c := range children {
+		if d.targets[c.value.Index] {
+			removed[i] = true
+			d.count++
+		} else if c.value.Type == JSONTypeJSON {
+			d.walk(c.value)
+		}
+	}
+	d.edits = append(d.edits, removeChildren(d.json, children, removed)...)
+}
+
+// childSpan is an object member or array element of a container
+type childSpan struct {
+	start int    // start of the member name, or of the element
+	value Result // the value, with Index set to its offset
+	end   int    // end of the value
+}
+
+// childSpans returns the members or elements of container, with offsets
+// relative to json
+func childSpans(json string, container Result) []childSpan {
+	if container.Type != JSONTypeJSON {
+		return nil
+	}
+	var spans []childSpan
+	if container.IsArray() {
+		for _, elem := range container.Array() {
+			spans = append(spans, childSpan{start: elem.Index, value: elem, end: elem.Index + len(elem.Raw)})
+		}
+		return spans
+	}
+
+	raw, base := container.Raw, container.Index
+	i := 1
+	for i < len(raw) {
+		i = skipWhitespaceJSON(raw, i)
+		if i >= len(raw) || raw[i] == '}' {
+			break
+		}
+		key, value, next := parseObjectMember(raw, i)
+		if key == "" && !value.Exists() {
+			break
+		}
+		value.Index = base + next - len(value.Raw)
+		spans = append(spans, childSpan{start: base + i, value: value, end: base + next})
+		i = skipWhitespaceJSON(raw, next)
+		if i < len(raw) && raw[i] == ',' {
+			i++
+		}
+	}
+	return spans
+}
+
+// removeChildren returns the edits removing the children marked in removed
+// together with the commas separating them from their neighbours. The
+// whitespace around the kept children is preserved, so removing a member
+// written on its own line removes the whole line.
+func removeChildren(json string, children []childSpan, removed []bool) []edit {
+	var edits []edit
+	for i := 0; i < len(children); {
+		if !removed[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(children) && removed[j] {
+			j++
+		}
+		// children[i:j] is a run of removed children
+		switch {
+		case j < len(children):
+			// remove up to the next kept child, including the comma after the run
+			edits = append(edits, edit{start: children[i].start, end: children[j].start})
+		case i > 0:
+			// the run ends the container: remove the comma before it
+			edits = append(edits, edit{start: children[i-1].end, end: children[j-1].end})
+		default:
+			// every child is removed: empty the container, e.g. [ 1, 2 ] becomes []
+			open := children[0].start - 1
+			for isSpaceJSON(json[open]) {
+				open--
+			}
+			close := skipWhitespaceJSON(json, children[j-1].end)
+			edits = append(edits, edit{start: open + 1, end: close})
+		}
+		i = j
+	}
+	return edits
+}
+
+// TransformFunc is called by Transform for every selected node. It returns the
+// replacement value and whether to keep the node; returning false removes the
+// node from its parent object or array.
+type TransformFunc func(node Node) (value Result, keep bool)
+
+// Transform calls fn for every node selected by path and returns the document
+// rewritten with the values fn returns, removing the nodes fn drops.
+//
+// All changes are applied to the original text in a single pass. Returning
+// node.Value unchanged leaves the node as is. When a selected node lies inside
+// another selected node that is replaced or removed, the change to the outer
+// node wins. The root value cannot be removed.
+//
+// The replacement is written from its Type and Str or Num, so a modified copy
+// of node.Value can be returned; Raw is kept when it still encodes the same
+// value, preserving the original spelling.
+func Transform(json, path string, fn TransformFunc) (string, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return "", err
+	}
+	return p.Transform(json, fn)
+}
+
+// Transform rewrites every node selected by the path, see Transform
+func (p *Path) Transform(json string, fn TransformFunc) (string, error) {
+	nodes, err := p.eval.evaluateNodes(json)
+	if err != nil {
+		return "", err
+	}
+
+	var edits []edit
+	drop := make(map[int]bool)
+	for _, n := range nodes {
+		value, keep := fn(n)
+		if !keep {
+			if len(n.Location) > 0 {
+				drop[n.Value.Index] = true
+			}
+			continue
+		}
+		if value == n.Value {
+			continue
+		}
+		start, end := valueSpan(n)
+		edits = append(edits, edit{start: start, end: end, text: encodeResult(value)})
+	}
+
+	removals, _ := deletionEdits(json, drop)
+	return applyEdits(json, append(edits, removals...)), nil
+}
+
+// encodeResult returns the JSON text of r
+func encodeResult(r Result) string {
+	switch r.Type {
+	case JSONTypeTrue:
+		return "true"
+	case JSONTypeFalse:
+		return "false"
+	case JSONTypeNumber:
+		if r.Raw != "" {
+			if num, err := strconv.ParseFloat(r.Raw, 64); err == nil && num == r.Num {
+				return r.Raw
+			}
+		}
+		return strconv.FormatFloat(r.Num, 'f', -1, 64)
+	case JSONTypeString:
+		if r.Raw != "" && r.Raw[0] == '"' {
+			if _, str := tostr(r.Raw); str == r.Str {
+				return r.Raw
+			}
+		}
+		return quoteString(r.Str)
+	case JSONTypeJSON:
+		if r.Raw != "" {
+			return r.Raw
+		}
+	}
+	return "null"
+}
@@ -0,0 +1,248 @@
+package jsonpath
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		json  string
+		path  string
+		value interface{}
+		want  string
+	}{
+		{"替换成员", `{"a": 1, "b": 2}`, "$.a", 10, `{"a": 10, "b": 2}`},
+		{"替换数组元素", `[1, 2, 3]`, "$[-1]", "x", `[1, 2, "x"]`},
+		{"替换对象", `{"a": {"b": 1}}`, "$.a", map[string]int{"c": 2}, `{"a": {"c":2}}`},
+		{"替换根节点", "{\"a\": 1}\n", "$", []int{1}, "[1]\n"},
+		{"通配符", `{"a": [1, 2, 3]}`, "$.a[*]", 0, `{"a": [0, 0, 0]}`},
+		{"过滤器", `[{"p": 1}, {"p": 20}]`, "$[?@.p > 10].p", 10, `[{"p": 1}, {"p": 10}]`},
+		{"后代", `{"a": {"id": 1}, "b": [{"id": 2}]}`, "$..id", nil, `{"a": {"id": null}, "b": [{"id": null}]}`},
+		{"外层节点优先", `{"a": {"a": 1}}`, "$..a", true, `{"a": true}`},
+		{"重复选择", `[1, 2]`, "$[0,0]", 5, `[5, 2]`},
+		{"无匹配", `{"a": 1}`, "$.b", 5, `{"a": 1}`},
+		{"不转义 HTML", `{"a": 1}`, "$.a", "<b>", `{"a": "<b>"}`},
+		{"转义字符串", `{"a\"b": "x\\y"}`, `$['a"b']`, "z", `{"a\"b": "z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Set(tt.json, tt.path, tt.value)
+			if err != nil {
+				t.Fatalf("Set(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("Set(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRaw(t *testing.T) {
+	got, err := SetRaw(`{"a": 1}`, "$.a", `{"b": [1, 2]}`)
+	if err != nil || got != `{"a": {"b": [1, 2]}}` {
+		t.Errorf("SetRaw() = %s, %v", got, err)
+	}
+
+	if _, err := SetRaw(`{"a": 1}`, "$.a", `{"b"`); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("SetRaw() error = %v, want ErrInvalidJSON", err)
+	}
+}
+
+func TestSetBytes(t *testing.T) {
+	got, err := SetBytes([]byte(`{"a": 1}`), "$.a", 2)
+	if err != nil || string(got) != `{"a": 2}` {
+		t.Errorf("SetBytes() = %s, %v", got, err)
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	if _, err := Set(`{}`, "$[", 1); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Set() error = %v, want ErrInvalidPath", err)
+	}
+	if _, err := Set(``, "$.a", 1); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("Set() error = %v, want ErrInvalidJSON", err)
+	}
+	if _, err := Set(`{}`, "$.a", func() {}); err == nil {
+		t.Error("Set() with unsupported value type returned no error")
+	}
+}
+
+func TestSetCreateMissing(t *testing.T) {
+	opts := &SetOptions{CreateMissing: true}
+	tests := []struct {
+		name    string
+		json    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{"已存在", `{"a": {"b": 1}}`, "$.a.b", `{"a": {"b": 0}}`, nil},
+		{"缺少成员", `{"a": 1}`, "$.b", `{"a": 1, "b": 0}`, nil},
+		{"空对象", `{}`, "$.a", `{"a":0}`, nil},
+		{"空白对象", `{ }`, "$.a", `{"a":0 }`, nil},
+		{"嵌套创建", `{"a": {}}`, "$.a.b.c", `{"a": {"b": {"c": 0}}}`, nil},
+		{"数组中的对象", `{"a": [{}]}`, "$.a[0].b", `{"a": [{"b": 0}]}`, nil},
+		{"尾随换行", "{\"a\": 1\n}", "$.b", "{\"a\": 1, \"b\": 0\n}", nil},
+		{"非单一路径不创建", `{"a": {}}`, "$.*.b", `{"a": {}}`, nil},
+		{"不能创建数组元素", `{"a": []}`, "$.a[0]", "", ErrCannotCreate},
+		{"嵌套数组元素", `{}`, "$.a[0]", "", ErrCannotCreate},
+		{"非对象", `{"a": 1}`, "$.a.b", "", ErrNotContainer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetWithOptions(tt.json, tt.path, 0, opts)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("SetWithOptions(%q) error = %v, want %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SetWithOptions(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name  string
+		json  string
+		path  string
+		want  string
+		wantN int
+	}{
+		{"删除成员", `{"a": 1, "b": 2, "c": 3}`, "$.b", `{"a": 1, "c": 3}`, 1},
+		{"删除首个成员", `{"a": 1, "b": 2}`, "$.a", `{"b": 2}`, 1},
+		{"删除末尾成员", `{"a": 1, "b": 2}`, "$.b", `{"a": 1}`, 1},
+		{"删除唯一成员", `{ "a": 1 }`, "$.a", `{}`, 1},
+		{"删除数组元素", `[1, 2, 3]`, "$[1]", `[1, 3]`, 1},
+		{"删除多个元素", `[0, 1, 2, 3, 4]`, "$[0, 2, 4]", `[1, 3]`, 3},
+		{"删除连续元素", `[0, 1, 2, 3]`, "$[1:3]", `[0, 3]`, 2},
+		{"倒序选择", `[0, 1, 2, 3]`, "$[3, 0, 1]", `[2]`, 3},
+		{"重复选择", `[0, 1, 2]`, "$[0, 0, -3]", `[1, 2]`, 1},
+		{"全部删除", `[0, 1, 2]`, "$[*]", `[]`, 3},
+		{"过滤器", `{"book": [{"price": 8}, {"price": 120}, {"price": 9}]}`, "$..book[?@.price > 100]", `{"book": [{"price": 8}, {"price": 9}]}`, 1},
+		{"后代", `{"password": "x", "user": {"name": "a", "password": "y"}}`, "$..password", `{"user": {"name": "a"}}`, 2},
+		{"嵌套选择只计外层", `{"a": {"a": 1}, "b": 2}`, "$..a", `{"b": 2}`, 1},
+		{"根节点不删除", `{"a": 1}`, "$", `{"a": 1}`, 0},
+		{"无匹配", `{"a": 1}`, "$.b", `{"a": 1}`, 0},
+		{"保留换行", "{\n  \"a\": 1,\n  \"b\": 2\n}", "$.b", "{\n  \"a\": 1\n}", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n, err := Delete(tt.json, tt.path)
+			if err != nil {
+				t.Fatalf("Delete(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want || n != tt.wantN {
+				t.Errorf("Delete(%q) = %s, %d, want %s, %d", tt.path, got, n, tt.want, tt.wantN)
+			}
+		})
+	}
+}
+
+func TestDeleteErrors(t *testing.T) {
+	if _, _, err := Delete(`{}`, "$["); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Delete() error = %v, want ErrInvalidPath", err)
+	}
+	if _, _, err := DeleteBytes([]byte(`x`), "$.a"); !errors.Is(err, ErrInvalidJSON) {
+		t.Errorf("DeleteBytes() error = %v, want ErrInvalidJSON", err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		path string
+		fn   TransformFunc
+		want string
+	}{
+		{
+			name: "修剪字符串",
+			json: `{"a": " x ", "b": ["  y", "z"]}`,
+			path: "$..*",
+			fn: func(n Node) (Result, bool) {
+				v := n.Value
+				if v.IsString() {
+					v.Str = strings.TrimSpace(v.Str)
+				}
+				return v, true
+			},
+			want: `{"a": "x", "b": ["y", "z"]}`,
+		},
+		{
+			name: "换算数值",
+			json: `[{"cents": 150}, {"cents": 99}]`,
+			path: "$[*].cents",
+			fn: func(n Node) (Result, bool) {
+				return Result{Type: JSONTypeNumber, Num: n.Value.Num / 100}, true
+			},
+			want: `[{"cents": 1.5}, {"cents": 0.99}]`,
+		},
+		{
+			name: "删除节点",
+			json: `[1, -2, 3, -4]`,
+			path: "$[*]",
+			fn: func(n Node) (Result, bool) {
+				return n.Value, n.Value.Num > 0
+			},
+			want: `[1, 3]`,
+		},
+		{
+			name: "替换与删除同时进行",
+			json: `{"a": 1, "b": 2, "c": 3}`,
+			path: "$.*",
+			fn: func(n Node) (Result, bool) {
+				if n.Location.String() == "$['b']" {
+					return Result{}, false
+				}
+				return Result{Type: JSONTypeString, Str: n.Location.String()}, true
+			},
+			want: `{"a": "$['a']", "c": "$['c']"}`,
+		},
+		{
+			name: "保留原始拼写",
+			json: `{"n": 1.50, "s": "é"}`,
+			path: "$.*",
+			fn: func(n Node) (Result, bool) {
+				return Result{Type: n.Value.Type, Raw: n.Value.Raw, Str: n.Value.Str, Num: n.Value.Num}, true
+			},
+			want: `{"n": 1.50, "s": "é"}`,
+		},
+		{
+			name: "外层修改优先",
+			json: `{"a": {"b": 1}}`,
+			path: "$..*",
+			fn: func(n Node) (Result, bool) {
+				return Result{Type: JSONTypeJSON, Raw: "[]"}, true
+			},
+			want: `{"a": []}`,
+		},
+		{
+			name: "写入 null",
+			json: `[1]`,
+			path: "$[0]",
+			fn: func(n Node) (Result, bool) {
+				return Result{}, true
+			},
+			want: `[null]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Transform(tt.json, tt.path, tt.fn)
+			if err != nil {
+				t.Fatalf("Transform(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("Transform(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Sentinel errors reported by the error-returning query API. Use errors.Is to
+// check the category of an error and errors.As to get the details.
+var (
+	// ErrInvalidPath is reported when a JSONPath expression cannot be parsed
+	ErrInvalidPath = errors.New("jsonpath: invalid path")
+	// ErrInvalidJSON is reported when the queried document is not valid JSON
+	ErrInvalidJSON = errors.New("jsonpath: invalid json")
+	// ErrFunction is reported when a function extension cannot be evaluated
+	ErrFunction = errors.New("jsonpath: function error")
+	// ErrNoMatch is reported when a query that must select a node selects none
+	ErrNoMatch = errors.New("jsonpath: no match")
+)
+
+// PathError describes a JSONPath expression that could not be parsed
+type PathError struct {
+	// Path is the expression that failed to parse
+	Path string
+	// Err is the underlying parser error
+	Err error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("jsonpath: invalid path %q: %v", e.Path, e.Err)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrInvalidPath
+func (e *PathError) Is(target error) bool {
+	return target == ErrInvalidPath
+}
+
+// JSONError describes a document that could not be parsed as JSON
+type JSONError struct {
+	// Offset is the byte offset in the document where the error was found
+	Offset int
+	// Line is the 1-based line number of Offset
+	Line int
+	// Column is the 1-based column of Offset, counted in characters
+	Column int
+	// Msg describes the problem and what was expected
+	Msg string
+}
+
+// newJSONError returns a JSONError at offset in json
+func newJSONError(json string, offset int, msg string) *JSONError {
+	line, lineStart := 1, 0
+	for i := 0; i < offset && i < len(json); i++ {
+		if json[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	end := offset
+	if end > len(json) {
+		end = len(json)
+	}
+	return &JSONError{
+		Offset: offset,
+		Line:   line,
+		Column: utf8.RuneCountInString(json[lineStart:end]) + 1,
+		Msg:    msg,
+	}
+}
+
+func (e *JSONError) Error() string {
+	return fmt.Sprintf("jsonpath: invalid json at line %d, column %d (offset %d): %s", e.Line, e.Column, e.Offset, e.Msg)
+}
+
+// Is reports whether target is ErrInvalidJSON
+func (e *JSONError) Is(target error) bool {
+	return target == ErrInvalidJSON
+}
+
+// FunctionError describes a function extension call that could not be evaluated,
+// such as an unknown function, a wrong argument count or a type mismatch
+type FunctionError struct {
+	// Name is the function name
+	Name string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *FunctionError) Error() string {
+	return "jsonpath: " + e.Err.Error()
+}
+
+func (e *FunctionError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrFunction
+func (e *FunctionError) Is(target error) bool {
+	return target == ErrFunction
+}
@@ -0,0 +1,109 @@
+package jsonpath
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestQueryAllErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		path    string
+		wantErr error
+		wantLen int
+	}{
+		{"无错误", `{"a": [1, 2]}`, "$.a[*]", nil, 2},
+		{"无匹配", `{"a": [1, 2]}`, "$.b", nil, 0},
+		{"非法路径", `{"a": 1}`, "$.a[", ErrInvalidPath, 0},
+		{"缺少根标识符", `{"a": 1}`, "a", ErrInvalidPath, 0},
+		{"空文档", ``, "$", ErrInvalidJSON, 0},
+		{"空白文档", " \n\t", "$.a", ErrInvalidJSON, 0},
+		{"非法文档", `<xml/>`, "$", ErrInvalidJSON, 0},
+		{"非法字面量", `{"a":tru}`, "$.a", ErrInvalidJSON, 0},
+		{"多余的逗号", `{"a":1,,"b":2}`, "$.a", ErrInvalidJSON, 0},
+		{"未闭合的数组", `{"a": [1, 2`, "$.a[0]", ErrInvalidJSON, 0},
+		{"尾随内容", `{"a":1}garbage`, "$.a", ErrInvalidJSON, 0},
+		{"超出匹配位置的错误", `[1, 2, x]`, "$[0]", ErrInvalidJSON, 0},
+		{"未知函数", `[1, 2]`, "$[?unknown(@) == 1]", ErrFunction, 0},
+		{"参数个数错误", `["a"]`, "$[?length(@, @) == 1]", ErrFunction, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryAll(tt.json, tt.path)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("QueryAll(%q) error = %v, want %v", tt.path, err, tt.wantErr)
+			}
+			if len(got) != tt.wantLen {
+				t.Errorf("QueryAll(%q) len = %d, want %d", tt.path, len(got), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestQueryAllErrorDetails(t *testing.T) {
+	_, err := QueryAll(`{}`, "$.a[")
+	var pathErr *PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "$.a[" {
+		t.Errorf("QueryAll() error = %v, want *PathError for %q", err, "$.a[")
+	}
+
+	_, err = QueryAll(`  x`, "$")
+	var jsonErr *JSONError
+	if !errors.As(err, &jsonErr) || jsonErr.Offset != 2 {
+		t.Errorf("QueryAll() error = %v, want *JSONError at offset 2", err)
+	}
+
+	_, err = QueryAll(`[1]`, "$[?nope(@)]")
+	var fnErr *FunctionError
+	if !errors.As(err, &fnErr) || fnErr.Name != "nope" {
+		t.Errorf("QueryAll() error = %v, want *FunctionError for nope()", err)
+	}
+}
+
+func TestQueryOne(t *testing.T) {
+	r, err := QueryOne(`{"a": [1, 2]}`, "$.a[*]")
+	if err != nil || r.Int() != 1 {
+		t.Errorf("QueryOne() = %v, %v, want 1, nil", r, err)
+	}
+
+	r, err = QueryOne(`{"a": [1, 2]}`, "$.b")
+	if err != nil || r.Exists() {
+		t.Errorf("QueryOne() = %v, %v, want empty result, nil", r, err)
+	}
+
+	if _, err = QueryOne(`{}`, "$$"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("QueryOne() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestQueryMalformedJSON(t *testing.T) {
+	docs := []string{`{"a":tru}`, `{"a":1,,"b":2}`, `{"a": [1, 2`, `{"a":1}garbage`}
+	p := MustCompile("$.a")
+
+	for _, json := range docs {
+		var jsonErr *JSONError
+		if r, err := p.QueryOne(json); !errors.As(err, &jsonErr) || r.Exists() {
+			t.Errorf("QueryOne(%q) = %v, %v, want *JSONError", json, r, err)
+		}
+		if r, err := QueryOne(json, "$.a"); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("QueryOne(%q) = %v, %v, want ErrInvalidJSON", json, r, err)
+		}
+		if _, err := p.QueryContext(context.Background(), json); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("QueryContext(%q) error = %v, want ErrInvalidJSON", json, err)
+		}
+		if _, err := p.QueryNodes(json); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("QueryNodes(%q) error = %v, want ErrInvalidJSON", json, err)
+		}
+		called := false
+		err := p.ForEachMatch(json, func(Result) bool {
+			called = true
+			return true
+		})
+		if !errors.Is(err, ErrInvalidJSON) || called {
+			t.Errorf("ForEachMatch(%q) error = %v, called = %v, want ErrInvalidJSON before any call", json, err, called)
+		}
+	}
+}
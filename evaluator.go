@@ -1,157 +1,413 @@
 package jsonpath
 
 import (
+	"context"
+	"fmt"
 	"strconv"
 )
 
-// Evaluator evaluates JSONPath expressions against JSON data
+// Evaluator evaluates a parsed JSONPath query against JSON documents.
+//
+// An Evaluator holds no per-document state and is safe for concurrent use.
 type Evaluator struct {
-	json  string
-	query *Query
+	query    *Query
+	segments []compiledSegment
+	opts     Options
 }
 
-// NewEvaluator creates a new evaluator for the given JSON and query
-func NewEvaluator(json string, query *Query) *Evaluator {
+// NewEvaluator creates a new evaluator for the given query
+func NewEvaluator(query *Query) *Evaluator {
 	return &Evaluator{
-		json:  json,
-		query: query,
+		query:    query,
+		segments: compileSegments(query.Segments),
 	}
 }
 
-// Evaluate executes the query and returns all matching results
-func (e *Evaluator) Evaluate() []Result {
-	root := parseValue(e.json)
+// Evaluate executes the query against json and returns all matching results.
+//
+// Errors are ignored: malformed JSON yields no results and a function
+// extension that cannot be evaluated does not match. Use QueryAll to
+// inspect errors.
+func (e *Evaluator) Evaluate(json string) []Result {
+	results, _ := e.evaluate(json)
+	return results
+}
+
+// evaluate executes the query and returns the results along with the first
+// error encountered. Results are still returned when err is not nil.
+func (e *Evaluator) evaluate(json string) ([]Result, error) {
+	return e.evaluateContext(context.Background(), json)
+}
+
+// evaluateContext is like evaluate but stops with ctx.Err() once ctx is done
+func (e *Evaluator) evaluateContext(ctx context.Context, json string) ([]Result, error) {
+	ev, err := e.newEvaluation(json)
+	if err != nil {
+		return nil, err
+	}
+	ev.setContext(ctx)
+	nodes := ev.evaluate(e.segments)
+	if len(nodes) == 0 {
+		return nil, ev.err
+	}
+	results := make([]Result, len(nodes))
+	for i, n := range nodes {
+		results[i] = valueResult(n.value)
+	}
+	return results, ev.err
+}
+
+// evaluateNodes is like evaluate but also tracks the location of every node
+func (e *Evaluator) evaluateNodes(json string) ([]Node, error) {
+	ev, err := e.newEvaluation(json)
+	if err != nil {
+		return nil, err
+	}
+	ev.trackLocations = true
+	nodes := ev.evaluate(e.segments)
+	if len(nodes) == 0 {
+		return nil, ev.err
+	}
+	result := make([]Node, len(nodes))
+	for i, n := range nodes {
+		result[i] = Node{Location: n.loc, Value: valueResult(n.value)}
+	}
+	return result, ev.err
+}
+
+// forEach pushes the results to fn as they are found, stopping when fn
+// returns false. It returns the first error encountered.
+func (e *Evaluator) forEach(json string, fn func(r Result) bool) error {
+	ev, err := e.newEvaluation(json)
+	if err != nil {
+		return err
+	}
+	ev.forEach(e.segments, func(n node) bool {
+		return fn(valueResult(n.value))
+	})
+	return ev.err
+}
+
+// forEachNode is like forEach but also tracks the location of every node
+func (e *Evaluator) forEachNode(json string, fn func(n Node) bool) error {
+	ev, err := e.newEvaluation(json)
+	if err != nil {
+		return err
+	}
+	ev.trackLocations = true
+	ev.forEach(e.segments, func(n node) bool {
+		return fn(Node{Location: n.loc, Value: valueResult(n.value)})
+	})
+	return ev.err
+}
+
+// evaluation holds the state of a single query evaluation against one document
+type evaluation struct {
+	root Value
+	err  error // first error encountered, evaluation continues regardless
+
+	// ctx cancels the evaluation when done is closed
+	ctx  context.Context
+	done <-chan struct{}
+
+	// opts holds the limits of the evaluation
+	opts    *Options
+	visited int
+	results int
+	halted  bool
+
+	// trackLocations enables building the Location of every selected node
+	trackLocations bool
+
+	// absolute holds the results of the absolute queries of filters, which
+	// do not depend on the current node, keyed by query
+	absolute map[interface{}]interface{}
+}
+
+// newEvaluation prepares the evaluation of json, validating it first in strict mode
+func (e *Evaluator) newEvaluation(json string) (*evaluation, error) {
+	if e.opts.Strict {
+		if err := validate(json, e.opts.MaxDepth); err != nil {
+			return nil, err
+		}
+	}
+	root := parseValue(json)
 	if !root.Exists() {
+		return nil, invalidRootError(json)
+	}
+	return newEvaluation(jsonValue{r: root}, &e.opts), nil
+}
+
+// validateDocument reports malformed json before an evaluation that returns
+// errors. In strict mode newEvaluation validates json itself.
+func (e *Evaluator) validateDocument(json string) error {
+	if e.opts.Strict {
 		return nil
 	}
+	return validate(json, e.opts.MaxDepth)
+}
 
-	results := []Result{root}
+// newEvaluation prepares the evaluation of a document tree. A nil opts sets no limits.
+func newEvaluation(root Value, opts *Options) *evaluation {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &evaluation{
+		root: root,
+		opts: opts,
+	}
+}
 
-	for _, segment := range e.query.Segments {
-		results = e.evaluateSegment(results, segment)
-		if len(results) == 0 {
-			return nil
-		}
+// setContext makes the evaluation stop once ctx is done
+func (e *evaluation) setContext(ctx context.Context) {
+	e.ctx = ctx
+	e.done = ctx.Done()
+}
+
+// halt stops the evaluation with err, which replaces any error recorded before
+func (e *evaluation) halt(err error) {
+	if !e.halted {
+		e.err = err
+		e.halted = true
 	}
+}
 
-	return results
+// stopped reports whether the evaluation must stop because it was halted or
+// its context is done
+func (e *evaluation) stopped() bool {
+	if e.halted {
+		return true
+	}
+	if e.done == nil {
+		return false
+	}
+	select {
+	case <-e.done:
+		e.halt(e.ctx.Err())
+		return true
+	default:
+		return false
+	}
 }
 
-func (e *Evaluator) evaluateSegment(input []Result, segment *Segment) []Result {
-	var output []Result
+// visit counts a visited node at depth and reports whether the evaluation
+// can go on
+func (e *evaluation) visit(depth int) bool {
+	e.visited++
+	if max := e.opts.MaxNodes; max > 0 && e.visited > max {
+		e.halt(&LimitError{Limit: LimitNodes, Max: max})
+	}
+	if max := e.opts.MaxDepth; max > 0 && depth > max {
+		e.halt(&LimitError{Limit: LimitDepth, Max: max})
+	}
+	return !e.stopped()
+}
 
-	if segment.Type == DescendantSegment {
-		for _, result := range input {
-			descendants := e.evalDescendant(result, segment.Selectors)
-			output = append(output, descendants...)
-		}
-	} else {
-		for _, result := range input {
-			for _, selector := range segment.Selectors {
-				selected := e.evaluateSelector(result, selector)
-				output = append(output, selected...)
-			}
-		}
+// absoluteResult returns the memoised result of the absolute query key
+func (e *evaluation) absoluteResult(key interface{}) (interface{}, bool) {
+	result, ok := e.absolute[key]
+	return result, ok
+}
+
+// setAbsoluteResult memoises the result of the absolute query key
+func (e *evaluation) setAbsoluteResult(key, result interface{}) {
+	if e.absolute == nil {
+		e.absolute = make(map[interface{}]interface{})
 	}
+	e.absolute[key] = result
+}
 
-	return output
+// fail records err if no error has been recorded yet
+func (e *evaluation) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
 }
 
-// evalDescendant evaluates descendant segments (recursive)
-func (e *Evaluator) evalDescendant(result Result, selectors []*Selector) []Result {
-	var results []Result
-	e.collectDescendants(result, selectors, &results)
+// node is a value reached during evaluation, with its location when tracked
+type node struct {
+	loc   Location
+	value Value
+	depth int
+}
 
-	return results
+// evaluate returns the nodes selected by the compiled segments of a query
+func (e *evaluation) evaluate(segments []compiledSegment) []node {
+	var nodes []node
+	e.forEach(segments, func(n node) bool {
+		nodes = append(nodes, n)
+		return true
+	})
+	return nodes
 }
 
-// collectDescendants recursively collects descendant nodes
-func (e *Evaluator) collectDescendants(result Result, selectors []*Selector, results *[]Result) {
-	for _, selector := range selectors {
-		selected := e.evaluateSelector(result, selector)
-		*results = append(*results, selected...)
+// forEach pushes the nodes selected by segments to fn one by one, stopping
+// as soon as fn returns false
+func (e *evaluation) forEach(segments []compiledSegment, fn func(n node) bool) {
+	root := node{value: e.root}
+	if e.trackLocations {
+		root.loc = Location{}
 	}
+	e.forEachFrom(root, segments, fn)
+}
 
-	if result.IsArray() {
-		for _, elem := range result.Array() {
-			e.collectDescendants(elem, selectors, results)
+// forEachFrom is like forEach but applies segments to n instead of the root
+func (e *evaluation) forEachFrom(n node, segments []compiledSegment, fn func(n node) bool) {
+	e.walk(n, segments, func(n node) bool {
+		e.results++
+		if max := e.opts.MaxResults; max > 0 && e.results > max {
+			e.halt(&LimitError{Limit: LimitResults, Max: max})
+			return false
 		}
-	} else if result.IsObject() {
-		for _, kv := range result.MapKVList() {
-			e.collectDescendants(kv.Value, selectors, results)
+		return fn(n)
+	})
+}
+
+// walk applies segments to n depth first, which yields the nodes in the same
+// order as applying every segment to the whole nodelist of the previous one.
+// It returns false once emit has returned false.
+func (e *evaluation) walk(n node, segments []compiledSegment, emit func(n node) bool) bool {
+	if !e.visit(n.depth) {
+		return false
+	}
+	if len(segments) == 0 {
+		return emit(n)
+	}
+	segment, rest := segments[0], segments[1:]
+	next := func(child node) bool {
+		return e.walk(child, rest, emit)
+	}
+
+	if segment.descendant {
+		return e.walkDescendants(n, segment.selectors, next)
+	}
+	for _, selector := range segment.selectors {
+		cont := selector(e, n.value, func(step LocationStep, v Value) bool {
+			return next(e.child(n, step, v))
+		})
+		if !cont {
+			return false
 		}
 	}
+	return true
 }
 
-func (e *Evaluator) evaluateSelector(result Result, selector *Selector) []Result {
-	switch selector.Type {
-	case NameSelector:
-		return e.evalNameSelector(result, selector.Name)
-	case WildcardSelector:
-		return e.evalWildcardSelector(result)
-	case IndexSelector:
-		return e.evalIndexSelector(result, selector.Index)
-	case SliceSelector:
-		return e.evalSliceSelector(result, selector.Slice)
-	case FilterSelector:
-		return e.evalFilterSelector(result, selector.Filter)
-	default:
-		return nil
+// invalidRootError describes why json has no parsable root value
+func invalidRootError(json string) error {
+	i := skipWhitespaceJSON(json, 0)
+	if i >= len(json) {
+		return newJSONError(json, i, "unexpected end of input, expected value")
 	}
+	return newJSONError(json, i, fmt.Sprintf("unexpected %q, expected value", json[i]))
 }
 
-func (e *Evaluator) evalNameSelector(result Result, name string) []Result {
-	if !result.IsObject() {
-		return nil
-	}
-	m := result.Map()
-	if v, ok := m[name]; ok {
-		return []Result{v}
+// child returns the node reached from parent by step
+func (e *evaluation) child(parent node, step LocationStep, v Value) node {
+	n := node{value: v, depth: parent.depth + 1}
+	if e.trackLocations {
+		n.loc = parent.loc.Child(step)
 	}
-	return nil
+	return n
 }
 
-func (e *Evaluator) evalWildcardSelector(result Result) []Result {
-	if result.IsArray() {
-		return result.Array()
+// walkDescendants applies selectors to n and then to each of its descendants
+func (e *evaluation) walkDescendants(n node, selectors []selectorFunc, emit func(n node) bool) bool {
+	if !e.visit(n.depth) {
+		return false
 	}
-	if result.IsObject() {
-		var results []Result
-		for _, kv := range result.MapKVList() {
-			results = append(results, kv.Value)
+	for _, selector := range selectors {
+		cont := selector(e, n.value, func(step LocationStep, v Value) bool {
+			return emit(e.child(n, step, v))
+		})
+		if !cont {
+			return false
 		}
-		return results
 	}
-	return nil
+
+	cont := true
+	n.value.Each(func(step LocationStep, v Value) bool {
+		cont = e.walkDescendants(e.child(n, step, v), selectors, emit)
+		return cont
+	})
+	return cont
 }
 
-func (e *Evaluator) evalIndexSelector(result Result, index int) []Result {
-	if !result.IsArray() {
-		return nil
+// selectFunc receives each node chosen by a selector and the step leading to
+// it, and returns false to stop the selection
+type selectFunc func(step LocationStep, v Value) bool
+
+// selectAll evaluates selectors against v and returns the selected values
+func (e *evaluation) selectAll(v Value, selectors []selectorFunc) []Value {
+	var values []Value
+	for _, selector := range selectors {
+		selector(e, v, func(_ LocationStep, selected Value) bool {
+			values = append(values, selected)
+			return true
+		})
+	}
+	return values
+}
+
+// descendantsAll evaluates selectors against v and all of its descendants
+func (e *evaluation) descendantsAll(v Value, selectors []selectorFunc) []Value {
+	var values []Value
+	e.walkDescendants(node{value: v}, selectors, func(n node) bool {
+		values = append(values, n.value)
+		return true
+	})
+	return values
+}
+
+func (e *evaluation) evalNameSelector(v Value, name string, emit selectFunc) bool {
+	if v.Kind() != KindObject {
+		return true
+	}
+	if m, ok := v.Member(name); ok {
+		return emit(nameStep(name), m)
+	}
+	return true
+}
+
+func (e *evaluation) evalWildcardSelector(v Value, emit selectFunc) bool {
+	cont := true
+	v.Each(func(step LocationStep, child Value) bool {
+		cont = emit(step, child)
+		return cont
+	})
+	return cont
+}
+
+func (e *evaluation) evalIndexSelector(v Value, index int, emit selectFunc) bool {
+	if v.Kind() != KindArray {
+		return true
 	}
-	arr := result.Array()
-	length := len(arr)
 
 	// Handle negative indices
 	if index < 0 {
-		index = length + index
+		index = v.Len() + index
 	}
 
 	// Out of bounds returns empty (RFC 9535)
-	if index < 0 || index >= length {
-		return nil
+	if index < 0 {
+		return true
 	}
 
-	return []Result{arr[index]}
+	if elem, ok := v.Index(index); ok {
+		return emit(indexStep(index), elem)
+	}
+	return true
 }
 
-func (e *Evaluator) evalSliceSelector(result Result, slice *SliceParams) []Result {
-	if !result.IsArray() {
-		return nil
-	}
+// rangeValue is implemented by values that can select a slice without
+// looking up every selected element on its own
+type rangeValue interface {
+	eachInRange(start, end, step int, fn func(i int, elem Value) bool) bool
+}
 
-	arr := result.Array()
-	arrLen := len(arr)
+func (e *evaluation) evalSliceSelector(v Value, slice *SliceParams, emit selectFunc) bool {
+	if v.Kind() != KindArray {
+		return true
+	}
 
 	step := 1
 	if slice.Step != nil {
@@ -159,38 +415,79 @@ func (e *Evaluator) evalSliceSelector(result Result, slice *SliceParams) []Resul
 	}
 
 	if step == 0 {
-		return nil // RFC 9535: step=0 returns empty
+		return true // RFC 9535: step=0 returns empty
+	}
+
+	// Forward slices of JSON text are selected in a single scan
+	if r, ok := v.(rangeValue); ok && step > 0 {
+		start, end := 0, -1
+		if slice.Start != nil {
+			start = *slice.Start
+		}
+		if slice.End != nil {
+			end = *slice.End
+		}
+		if start < 0 || (slice.End != nil && end < 0) {
+			// Negative bounds need the length of the array
+			arrLen := v.Len()
+			start, end, _ = e.normalizeSliceBounds(slice.Start, slice.End, step, arrLen)
+		}
+		if end >= 0 && start >= end {
+			return true
+		}
+		return r.eachInRange(start, end, step, func(i int, elem Value) bool {
+			return emit(indexStep(i), elem)
+		})
+	}
+
+	// Backward slices of JSON text collect the element offsets once instead
+	// of scanning the array again for every element
+	if r, ok := v.(rangeValue); ok {
+		start, end, _ := e.normalizeSliceBounds(slice.Start, slice.End, step, v.Len())
+		if start <= end {
+			return true
+		}
+		return r.eachInRange(start, end, step, func(i int, elem Value) bool {
+			return emit(indexStep(i), elem)
+		})
 	}
 
+	arrLen := v.Len()
 	start, end, endIsDefault := e.normalizeSliceBounds(slice.Start, slice.End, step, arrLen)
 
-	var results []Result
+	emitIndex := func(i int) bool {
+		if elem, ok := v.Index(i); ok {
+			return emit(indexStep(i), elem)
+		}
+		return true
+	}
 	if step > 0 {
 		for i := start; i < end; i += step {
-			if i >= 0 && i < arrLen {
-				results = append(results, arr[i])
+			if i >= 0 && i < arrLen && !emitIndex(i) {
+				return false
 			}
 		}
 	} else {
 		if endIsDefault {
 			for i := start; i >= 0; i += step {
-				results = append(results, arr[i])
+				if !emitIndex(i) {
+					return false
+				}
 			}
 		} else {
 			for i := start; i > end; i += step {
-				if i >= 0 && i < arrLen {
-					results = append(results, arr[i])
+				if i >= 0 && i < arrLen && !emitIndex(i) {
+					return false
 				}
 			}
 		}
 	}
-
-	return results
+	return true
 }
 
 // normalizeSliceBounds normalizes slice bounds
 // Returns (start, end, endIsDefault)
-func (e *Evaluator) normalizeSliceBounds(start, end *int, step, arrLen int) (int, int, bool) {
+func (e *evaluation) normalizeSliceBounds(start, end *int, step, arrLen int) (int, int, bool) {
 	s := 0
 	if start != nil {
 		s = *start
@@ -213,9 +510,15 @@ func (e *Evaluator) normalizeSliceBounds(start, end *int, step, arrLen int) (int
 		endIsDefault = true
 	}
 
-	s = clamp(s, 0, arrLen-1)
-	if !endIsDefault {
+	// RFC 9535 bounds: [0, len] for forward slices, [-1, len-1] for backward ones
+	if step > 0 {
+		s = clamp(s, 0, arrLen)
 		en = clamp(en, 0, arrLen)
+	} else {
+		s = clamp(s, -1, arrLen-1)
+		if !endIsDefault {
+			en = clamp(en, -1, arrLen-1)
+		}
 	}
 
 	return s, en, endIsDefault
@@ -231,96 +534,23 @@ func clamp(v, min, max int) int {
 	return v
 }
 
-func (e *Evaluator) evalFilterSelector(result Result, filter *FilterExpr) []Result {
-	var results []Result
-
-	if result.IsArray() {
-		for _, elem := range result.Array() {
-			if e.evalFilterExpr(elem, filter) {
-				results = append(results, elem)
-			}
-		}
-	} else if result.IsObject() {
-		for _, kv := range result.MapKVList() {
-			if e.evalFilterExpr(kv.Value, filter) {
-				results = append(results, kv.Value)
-			}
-		}
-	}
-
-	return results
-}
-
-func (e *Evaluator) evalFilterExpr(currentNode Result, expr *FilterExpr) bool {
-	switch expr.Type {
-	case FilterLogicalOr:
-		return e.evalFilterExpr(currentNode, expr.Left) || e.evalFilterExpr(currentNode, expr.Right)
-	case FilterLogicalAnd:
-		return e.evalFilterExpr(currentNode, expr.Left) && e.evalFilterExpr(currentNode, expr.Right)
-	case FilterLogicalNot:
-		return !e.evalFilterExpr(currentNode, expr.Operand)
-	case FilterParen:
-		return e.evalFilterExpr(currentNode, expr.Operand)
-	case FilterComparison:
-		return e.evalComparison(currentNode, expr.Comp)
-	case FilterTest:
-		return e.evalTestExpr(currentNode, expr.Test)
-	}
-	return false
-}
-
-func (e *Evaluator) evalComparison(currentNode Result, comp *Comparison) bool {
-	left := e.evalComparable(currentNode, comp.Left)
-	right := e.evalComparable(currentNode, comp.Right)
-
-	leftEmpty := !left.Exists()
-	rightEmpty := !right.Exists()
-
-	if leftEmpty || rightEmpty {
-		switch comp.Op {
-		case CompEq:
-			return leftEmpty && rightEmpty
-		case CompNe:
-			return !leftEmpty || !rightEmpty
-		default:
+func (e *evaluation) evalFilterSelector(v Value, filter filterFunc, emit selectFunc) bool {
+	cont := true
+	v.Each(func(step LocationStep, child Value) bool {
+		if !e.visit(0) {
+			cont = false
 			return false
 		}
-	}
-
-	switch comp.Op {
-	case CompEq:
-		return e.compareEqual(left, right)
-	case CompNe:
-		return !e.compareEqual(left, right)
-	case CompLt:
-		return e.compareLess(left, right)
-	case CompLe:
-		return e.compareLess(left, right) || e.compareEqual(left, right)
-	case CompGt:
-		return !e.compareLess(left, right) && !e.compareEqual(left, right)
-	case CompGe:
-		return !e.compareLess(left, right)
-	}
-	return false
-}
-
-func (e *Evaluator) evalComparable(currentNode Result, c *Comparable) Result {
-	switch c.Type {
-	case ComparableLiteral:
-		return e.evalLiteral(c.Literal)
-	case ComparableSingularQuery:
-		return e.evalSingularQuery(currentNode, c.SingularQuery)
-	case ComparableFuncExpr:
-		result, err := e.evalFuncCall(currentNode, c.FuncExpr, FunctionValueTypeValue)
-		if err != nil {
-			return Result{}
+		if filter(e, child) {
+			cont = emit(step, child)
 		}
-		return result.(Result)
-	}
-	return Result{}
+		return cont
+	})
+	return cont
 }
 
-func (e *Evaluator) evalLiteral(lit *LiteralValue) Result {
+// literalResult returns the value of a literal
+func literalResult(lit *LiteralValue) Result {
 	switch lit.Type {
 	case LiteralString:
 		return Result{Type: JSONTypeString, Str: lit.Value}
@@ -328,131 +558,11 @@ func (e *Evaluator) evalLiteral(lit *LiteralValue) Result {
 		num, _ := strconv.ParseFloat(lit.Value, 64)
 		return Result{Type: JSONTypeNumber, Num: num, Raw: lit.Value}
 	case LiteralTrue:
-		return Result{Type: JSONTypeTrue}
+		return Result{Type: JSONTypeTrue, Raw: "true"}
 	case LiteralFalse:
-		return Result{Type: JSONTypeFalse}
+		return Result{Type: JSONTypeFalse, Raw: "false"}
 	case LiteralNull:
-		return Result{Type: JSONTypeNull}
+		return Result{Type: JSONTypeNull, Raw: "null"}
 	}
 	return Result{}
 }
-
-func (e *Evaluator) evalSingularQuery(currentNode Result, query *SingularQuery) Result {
-	results := e.evalQuerySegments(currentNode, query.Relative, query.Segments)
-	if len(results) == 0 {
-		return Result{}
-	}
-	return results[0]
-}
-
-// evalQuerySegments evaluates segments (shared logic for singular/filter queries)
-func (e *Evaluator) evalQuerySegments(currentNode Result, relative bool, segments []*SingularSegment) []Result {
-	var results []Result
-	if relative {
-		results = []Result{currentNode}
-	} else {
-		results = []Result{parseValue(e.json)}
-	}
-
-	for _, seg := range segments {
-		var newResults []Result
-		for _, r := range results {
-			switch seg.Type {
-			case SingularNameSegment:
-				selected := e.evalNameSelector(r, seg.Name)
-				newResults = append(newResults, selected...)
-			case SingularIndexSegment:
-				selected := e.evalIndexSelector(r, seg.Index)
-				newResults = append(newResults, selected...)
-			}
-		}
-		results = newResults
-		if len(results) == 0 {
-			return nil
-		}
-	}
-	return results
-}
-
-func (e *Evaluator) evalTestExpr(currentNode Result, test *TestExpr) bool {
-	if test.FilterQuery != nil {
-		return e.evalFilterQueryTest(currentNode, test.FilterQuery)
-	}
-	if test.FuncExpr != nil {
-		result, err := e.evalFuncCall(currentNode, test.FuncExpr, FunctionValueTypeLogical)
-		if err != nil {
-			return false
-		}
-		if logical, ok := result.(bool); ok {
-			return logical
-		}
-		if nodes, ok := result.([]Result); ok {
-			return len(nodes) > 0
-		}
-		return false
-	}
-	return false
-}
-
-func (e *Evaluator) evalFilterQueryTest(currentNode Result, fq *FilterQuery) bool {
-	results := e.evalFilterQuery(currentNode, fq)
-	return len(results) > 0
-}
-
-func (e *Evaluator) evalFilterQuery(currentNode Result, fq *FilterQuery) []Result {
-	var results []Result
-	if fq.Relative {
-		results = []Result{currentNode}
-	} else {
-		results = []Result{parseValue(e.json)}
-	}
-
-	for _, seg := range fq.Segments {
-		var newResults []Result
-		for _, r := range results {
-			for _, selector := range seg.Selectors {
-				selected := e.evaluateSelector(r, selector)
-				newResults = append(newResults, selected...)
-			}
-		}
-		results = newResults
-		if len(results) == 0 {
-			return nil
-		}
-	}
-	return results
-}
-
-func (e *Evaluator) compareEqual(a, b Result) bool {
-	if a.Type != b.Type {
-		return false
-	}
-
-	switch a.Type {
-	case JSONTypeNull:
-		return true
-	case JSONTypeTrue, JSONTypeFalse:
-		return a.Type == b.Type
-	case JSONTypeNumber:
-		return a.Num == b.Num
-	case JSONTypeString:
-		return a.Str == b.Str
-	case JSONTypeJSON:
-		return a.Raw == b.Raw
-	}
-	return false
-}
-
-func (e *Evaluator) compareLess(a, b Result) bool {
-	if a.Type != b.Type {
-		return false
-	}
-
-	switch a.Type {
-	case JSONTypeNumber:
-		return a.Num < b.Num
-	case JSONTypeString:
-		return a.Str < b.Str
-	}
-	return false
-}
@@ -0,0 +1,288 @@
+package jsonpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEvaluateComparisons(t *testing.T) {
+	json := `{"a": [1, "b", null, true, [1], {"x": 1}], "o": {"x": 1}, "l": [1, 2], "p": {"x": 1, "y": 2}}`
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"不同类型不可比较", "$.a[?@ > 0]", 1},
+		{"字符串比较", `$.a[?@ >= "a"]`, 1},
+		{"null 字面量", "$.a[?@ == null]", 1},
+		{"数组相等", "$.a[?@ == $.a[4]]", 1},
+		{"对象相等", "$.a[?@ == $.o]", 1},
+		{"数组长度不同", "$.a[?@ == $.l]", 0},
+		{"对象成员不同", "$.a[?@ == $.p]", 0},
+		{"两边都为空", "$.a[?@.missing <= $.nothing]", 6},
+		{"一边为空", "$.a[?@.missing < 1]", 0},
+		{"不等于空", "$.a[?@.x != $.nothing]", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(GetMany(json, tt.path)); got != tt.want {
+				t.Errorf("GetMany(%q) returned %d results, want %d", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestComparisonRules pins the RFC 9535 §2.3.5.2.2 rules for comparing
+// values of JSON text: arrays and objects compare by value, null is a value
+// and only numbers and strings are ordered
+func TestComparisonRules(t *testing.T) {
+	json := `{
+		"items": [{"v": [1, 2]}, {"v": [ 1,2 ]}, {"v": {"a": 1, "b": 2}}, {"v": {"b": 2, "a": 1}}, {"v": null}, {}, {"v": 5}, {"v": "x"}],
+		"arr": [1,2],
+		"obj": {"a":1,"b":2}
+	}`
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"数组按值相等", "$.items[?@.v == $.arr]", 2},
+		{"对象相等与成员顺序无关", "$.items[?@.v == $.obj]", 2},
+		{"null 字面量等于 null", "$.items[?@.v == null]", 1},
+		{"缺失的值不等于 null", "$.items[?@.v != null]", 7},
+		{"大于只比较数字", "$.items[?@.v > 3]", 1},
+		{"大于等于只比较字符串", `$.items[?@.v >= "a"]`, 1},
+		{"数组只在相等时满足小于等于", "$.items[?@.v <= $.arr]", 2},
+		{"对象没有大小", "$.items[?@.v > $.obj]", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(GetMany(json, tt.path)); got != tt.want {
+				t.Errorf("GetMany(%q) returned %d results, want %d", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForEachMatch(t *testing.T) {
+	json := `{"a": [{"b": 1}, {"b": [2, 3]}, {"c": {"b": 4}}], "b": 5}`
+
+	for _, path := range []string{"$..b", "$.a[*]..b", "$..[0,1]", "$.a[?@.b].b", "$..*"} {
+		var got []string
+		if err := ForEachMatch(json, path, func(r Result) bool {
+			got = append(got, r.Raw)
+			return true
+		}); err != nil {
+			t.Fatalf("ForEachMatch(%q) error = %v", path, err)
+		}
+		var want []string
+		for _, r := range GetMany(json, path) {
+			want = append(want, r.Raw)
+		}
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("ForEachMatch(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	count := 0
+	if err := ForEachMatch(json, "$..b", func(r Result) bool {
+		count++
+		return count < 2
+	}); err != nil || count != 2 {
+		t.Errorf("ForEachMatch() called fn %d times, %v, want 2", count, err)
+	}
+
+	if err := ForEachMatch(json, "$[", func(Result) bool { return true }); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("ForEachMatch() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+// countingValue counts the children visited through it
+type countingValue struct {
+	Value
+	visited *int
+}
+
+func (c countingValue) Each(fn func(step LocationStep, v Value) bool) {
+	c.Value.Each(func(step LocationStep, v Value) bool {
+		*c.visited++
+		return fn(step, countingValue{v, c.visited})
+	})
+}
+
+func (c countingValue) Member(name string) (Value, bool) {
+	v, ok := c.Value.Member(name)
+	if ok {
+		*c.visited++
+		v = countingValue{v, c.visited}
+	}
+	return v, ok
+}
+
+func (c countingValue) Index(i int) (Value, bool) {
+	v, ok := c.Value.Index(i)
+	if ok {
+		*c.visited++
+		v = countingValue{v, c.visited}
+	}
+	return v, ok
+}
+
+func TestForEachStopsWalk(t *testing.T) {
+	json := "[" + strings.Repeat(`{"a": [1, 2, 3]}, `, 1000) + "{}]"
+	query, err := Parse("$..a[?@ > 1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	visited := 0
+	ev := newEvaluation(countingValue{jsonValue{r: parseValue(json)}, &visited}, nil)
+	found := 0
+	ev.forEach(compileSegments(query.Segments), func(n node) bool {
+		found++
+		return false
+	})
+	if found != 1 || visited > 10 {
+		t.Errorf("forEach() found %d nodes after visiting %d, want 1 after a few", found, visited)
+	}
+}
+
+func TestQueryContext(t *testing.T) {
+	json := `{"a": [1, 2, 3]}`
+
+	results, err := QueryContext(context.Background(), json, "$.a[?@ > 1]")
+	if err != nil || len(results) != 2 {
+		t.Errorf("QueryContext() = %v, %v, want 2 results", results, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if results, err := QueryContext(ctx, json, "$..*"); err != context.Canceled || results != nil {
+		t.Errorf("QueryContext() = %v, %v, want context.Canceled", results, err)
+	}
+
+	if _, err := QueryContext(ctx, json, "$["); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("QueryContext() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+// cancellingValue cancels a context after a number of visited children
+type cancellingValue struct {
+	Value
+	visited *int
+	after   int
+	cancel  context.CancelFunc
+}
+
+func (c cancellingValue) Each(fn func(step LocationStep, v Value) bool) {
+	c.Value.Each(func(step LocationStep, v Value) bool {
+		*c.visited++
+		if *c.visited == c.after {
+			c.cancel()
+		}
+		return fn(step, cancellingValue{v, c.visited, c.after, c.cancel})
+	})
+}
+
+func TestEvaluationCancelled(t *testing.T) {
+	json := "[" + strings.Repeat(`{"a": [1, 2, {"b": 3}]}, `, 1000) + "{}]"
+
+	for _, path := range []string{"$..*", "$[?@.a[?@.b]]", "$[*].a[*]"} {
+		query, err := Parse(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		visited := 0
+		ev := newEvaluation(cancellingValue{jsonValue{r: parseValue(json)}, &visited, 10, cancel}, nil)
+		ev.setContext(ctx)
+		ev.evaluate(compileSegments(query.Segments))
+		if ev.err != context.Canceled || visited > 20 {
+			t.Errorf("%s: error %v after visiting %d nodes, want context.Canceled right after 10", path, ev.err, visited)
+		}
+	}
+}
+
+func TestAbsoluteQueriesMemoised(t *testing.T) {
+	json := `{"limits": {"max": 3}, "items": [` + strings.Repeat(`{"price": 1}, {"price": 5}, `, 500) + `{"price": 2}]}`
+
+	tests := []struct {
+		name string
+		path string
+		want int
+		// maxVisited allows visiting every candidate and its price, and the
+		// absolute query once instead of once per candidate
+		maxVisited int
+	}{
+		{"单值查询", "$.items[?@.price < $.limits.max]", 501, 2100},
+		{"过滤查询", "$.items[?count($..max) == 1 && @.price > 1]", 501, 5000},
+		{"存在性测试", "$.items[?$.limits.max && @.price == 2]", 1, 2100},
+		{"不存在", "$.items[?@.price < $.limits.min]", 0, 2100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := Parse(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			visited := 0
+			ev := newEvaluation(countingValue{jsonValue{r: parseValue(json)}, &visited}, nil)
+			nodes := ev.evaluate(compileSegments(query.Segments))
+			if len(nodes) != tt.want {
+				t.Errorf("evaluate() returned %d nodes, want %d", len(nodes), tt.want)
+			}
+			if visited > tt.maxVisited {
+				t.Errorf("evaluate() visited %d nodes, want the absolute query evaluated once", visited)
+			}
+		})
+	}
+}
+
+func BenchmarkFilterLargeArray(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`[`)
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"id": %d, "price": %d, "tags": ["a", "b"], "name": "item %d"}`, i, i%100, i)
+	}
+	sb.WriteString(`]`)
+	doc, err := ParseDocument(sb.String())
+	if err != nil {
+		b.Fatal(err)
+	}
+	p := MustCompile(`$[?@.price > 50 && @.price < 60.5 || length(@.tags) == 3 && match(@.name, 'item 1.*')].id`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc.Query(p)
+	}
+}
+
+func BenchmarkCompareLargeArrays(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`[`)
+	for i := 0; i < 20000; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"id": %d, "tags": ["a", "b"]}`, i)
+	}
+	sb.WriteString(`]`)
+	json := `{"a": [` + sb.String() + `], "b": ` + sb.String() + `}`
+	p := MustCompile(`$.a[?@ == $.b]`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.GetMany(json)
+	}
+}
@@ -2,6 +2,7 @@ package jsonpath_test
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/saltfishpr/jsonpath"
 )
@@ -288,3 +289,76 @@ func ExampleGetMany_functions() {
 	// 9. search() - 类别包含 'e' 的产品
 	// map[category:vegetable id:C003 name:Carrot]
 }
+
+func ExampleCompile() {
+	p := jsonpath.MustCompile("$.store.book[?@.price < 10].title")
+
+	for _, v := range p.GetMany(rfcExampleJSON) {
+		fmt.Println(v.String())
+	}
+	fmt.Println(p.Get(`{"store": {"book": [{"title": "Cheap", "price": 1}]}}`))
+
+	// Output:
+	// Sayings of the Century
+	// Moby Dick
+	// Cheap
+}
+
+func ExampleQueryNodes() {
+	nodes, err := jsonpath.QueryNodes(rfcExampleJSON, "$..book[?@.price > 20].title")
+	if err != nil {
+		panic(err)
+	}
+	for _, n := range nodes {
+		fmt.Println(n.Location, n.Value)
+	}
+
+	// Output:
+	// $['store']['book'][3]['title'] The Lord of the Rings
+}
+
+func ExampleSet() {
+	json := `{"store": {"book": [{"title": "A", "price": 8}, {"title": "B", "price": 30}]}}`
+
+	json, err := jsonpath.Set(json, "$.store.book[?@.price > 20].price", 20)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(json)
+
+	json, err = jsonpath.SetWithOptions(json, "$.store.owner.name", "Alice", &jsonpath.SetOptions{CreateMissing: true})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(json)
+
+	// Output:
+	// {"store": {"book": [{"title": "A", "price": 8}, {"title": "B", "price": 20}]}}
+	// {"store": {"book": [{"title": "A", "price": 8}, {"title": "B", "price": 20}], "owner": {"name": "Alice"}}}
+}
+
+func ExampleTransform() {
+	json := `{"items": [{"name": " pen ", "cents": 150}, {"name": "ink", "cents": 0}]}`
+
+	json, err := jsonpath.Transform(json, "$.items[*]", func(n jsonpath.Node) (jsonpath.Result, bool) {
+		if n.Value.Get("$.cents").Int() == 0 {
+			return n.Value, false // drop free items
+		}
+		return n.Value, true
+	})
+	if err != nil {
+		panic(err)
+	}
+	json, err = jsonpath.Transform(json, "$.items[*].name", func(n jsonpath.Node) (jsonpath.Result, bool) {
+		v := n.Value
+		v.Str = strings.TrimSpace(v.Str)
+		return v, true
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(json)
+
+	// Output:
+	// {"items": [{"name": "pen", "cents": 150}]}
+}
@@ -49,78 +49,129 @@ func init() {
 	registerValue()
 }
 
-func (e *Evaluator) evalFuncCall(currentNode Result, fn *FuncCall, expectedType FunctionValueType) (interface{}, error) {
+// compileFuncCall binds a function call to its signature and arguments.
+// Calls that cannot be evaluated fail each time they are evaluated.
+func compileFuncCall(fn *FuncCall) callFunc {
 	sig, exists := functionRegistry[fn.Name]
 	if !exists {
-		return nil, fmt.Errorf("unknown function: %s", fn.Name)
+		err := fmt.Errorf("unknown function: %s", fn.Name)
+		return func(*evaluation, Value) (interface{}, error) { return nil, err }
 	}
 
 	if len(fn.Args) != len(sig.ParamTypes) {
-		return nil, fmt.Errorf("%s() expects %d arguments, got %d",
+		err := fmt.Errorf("%s() expects %d arguments, got %d",
 			fn.Name, len(sig.ParamTypes), len(fn.Args))
+		return func(*evaluation, Value) (interface{}, error) { return nil, err }
 	}
 
-	args := make([]interface{}, len(fn.Args))
+	args := make([]callFunc, len(fn.Args))
 	for i, arg := range fn.Args {
-		val, err := e.evalFuncArg(currentNode, arg, sig.ParamTypes[i])
-		if err != nil {
-			return nil, fmt.Errorf("argument %d of %s(): %w", i+1, fn.Name, err)
-		}
-		args[i] = val
+		args[i] = compileFuncArg(arg, sig.ParamTypes[i])
 	}
+	checkRegex := isRegexFunction(fn.Name) && len(args) == 2
+
+	return func(e *evaluation, current Value) (interface{}, error) {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			val, err := arg(e, current)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d of %s(): %w", i+1, fn.Name, err)
+			}
+			values[i] = val
+		}
 
-	return sig.Handler(args)
+		if max := e.opts.MaxRegexLength; max > 0 && checkRegex {
+			if pattern, ok := values[1].(Result); ok && len(pattern.Str) > max {
+				err := &LimitError{Limit: LimitRegexLength, Max: max}
+				e.halt(err)
+				return nil, err
+			}
+		}
+
+		return sig.Handler(values)
+	}
 }
 
-func (e *Evaluator) evalFuncArg(currentNode Result, arg *FuncArg, expectedType FunctionValueType) (interface{}, error) {
+func compileFuncArg(arg *FuncArg, expectedType FunctionValueType) callFunc {
+	fail := func(err error) callFunc {
+		return func(*evaluation, Value) (interface{}, error) { return nil, err }
+	}
+
 	switch arg.Type {
 	case FuncArgLiteral:
 		// 字面量只能是 ValueType
 		if expectedType != FunctionValueTypeValue {
-			return nil, fmt.Errorf("literal cannot be converted to %s", expectedType)
+			return fail(fmt.Errorf("literal cannot be converted to %s", expectedType))
 		}
-		return e.evalLiteral(arg.Literal), nil
+		lit := literalResult(arg.Literal)
+		return func(*evaluation, Value) (interface{}, error) { return lit, nil }
 
 	case FuncArgFilterQuery:
-		nodes := e.evalFilterQuery(currentNode, arg.FilterQuery)
+		if sq := asSingularQuery(arg.FilterQuery); sq != nil && expectedType == FunctionValueTypeValue {
+			query := compileSingularQuery(sq)
+			return func(e *evaluation, current Value) (interface{}, error) {
+				if v := query(e, current); v != nil {
+					return valueResult(v), nil
+				}
+				return FunctionValueNothing, nil
+			}
+		}
+		query := compileFilterQuery(arg.FilterQuery)
 		switch expectedType {
 		case FunctionValueTypeValue:
-			if len(nodes) == 1 {
-				return nodes[0], nil
+			return func(e *evaluation, current Value) (interface{}, error) {
+				if values := query(e, current); len(values) == 1 {
+					return valueResult(values[0]), nil
+				}
+				return FunctionValueNothing, nil
 			}
-			return FunctionValueNothing, nil
 		case FunctionValueTypeLogical:
-			return len(nodes) > 0, nil
+			return func(e *evaluation, current Value) (interface{}, error) {
+				return len(query(e, current)) > 0, nil
+			}
 		case FunctionValueTypeNodes:
-			return nodes, nil
+			return func(e *evaluation, current Value) (interface{}, error) {
+				values := query(e, current)
+				nodes := make([]Result, len(values))
+				for i, v := range values {
+					nodes[i] = valueResult(v)
+				}
+				return nodes, nil
+			}
 		default:
-			return nil, fmt.Errorf("cannot convert nodes to %s", expectedType)
+			return fail(fmt.Errorf("cannot convert nodes to %s", expectedType))
 		}
 
 	case FuncArgLogicalExpr:
 		if expectedType != FunctionValueTypeLogical {
-			return nil, fmt.Errorf("logical expression cannot be converted to %s", expectedType)
+			return fail(fmt.Errorf("logical expression cannot be converted to %s", expectedType))
+		}
+		filter := compileFilter(arg.LogicalExpr)
+		return func(e *evaluation, current Value) (interface{}, error) {
+			return filter(e, current), nil
 		}
-		return e.evalFilterExpr(currentNode, arg.LogicalExpr), nil
 
 	case FuncArgFuncExpr:
 		fn := arg.FuncExpr
 		sig, exists := functionRegistry[fn.Name]
 		if !exists {
-			return nil, fmt.Errorf("unknown function: %s", fn.Name)
+			return fail(fmt.Errorf("unknown function: %s", fn.Name))
 		}
 		// 嵌套函数调用：期望类型必须与嵌套函数的返回类型匹配
-		resultAny, err := e.evalFuncCall(currentNode, fn, sig.ReturnType)
-		if err != nil {
-			return nil, err
-		}
-		// 类型兼容性检查
-		if expectedType != sig.ReturnType {
-			return nil, fmt.Errorf("type mismatch: %s() returns %s but %s is expected", fn.Name, sig.ReturnType, expectedType)
+		call := compileFuncCall(fn)
+		return func(e *evaluation, current Value) (interface{}, error) {
+			resultAny, err := call(e, current)
+			if err != nil {
+				return nil, err
+			}
+			// 类型兼容性检查
+			if expectedType != sig.ReturnType {
+				return nil, fmt.Errorf("type mismatch: %s() returns %s but %s is expected", fn.Name, sig.ReturnType, expectedType)
+			}
+			return resultAny, nil
 		}
-		return resultAny, nil
 
 	default:
-		return nil, fmt.Errorf("unknown function argument type")
+		return fail(fmt.Errorf("unknown function argument type"))
 	}
 }
@@ -0,0 +1,646 @@
+package jsonpath
+
+import (
+	"encoding/base64"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotAddressable is reported by the pointer queries when a selected value
+// is not stored in an addressable location, such as a map value or a field of
+// a struct passed by value
+var ErrNotAddressable = errors.New("jsonpath: value is not addressable")
+
+// EvaluateValues evaluates the query against a Go value and returns the
+// selected Go values.
+//
+// v may be built from map[string]interface{}, []interface{}, json.Number and
+// the other types produced by encoding/json, or from structs, which are read
+// through their exported fields following the json struct tags like
+// json.Marshal does. Values implementing json.Marshaler are queried through
+// their JSON encoding.
+//
+// Maps, slices and pointers are returned as is, so modifying them modifies v.
+func (e *Evaluator) EvaluateValues(v interface{}) ([]interface{}, error) {
+	ev := newEvaluation(newGoValue(goRoot(v)), &e.opts)
+	nodes := ev.evaluate(e.segments)
+	if len(nodes) == 0 {
+		return nil, ev.err
+	}
+	values := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		values[i] = interfaceOf(n.value)
+	}
+	return values, ev.err
+}
+
+// EvaluatePointers is like EvaluateValues but returns a pointer to the storage
+// of every selected value, through which the value can be replaced.
+//
+// v must be a pointer, a slice or a map for any value to be addressable.
+// Elements of slices, fields of addressable structs and values stored in
+// interface{} slots of those are addressable; map values are not. ErrNotAddressable
+// is returned for the first selected value that is not addressable.
+func (e *Evaluator) EvaluatePointers(v interface{}) ([]interface{}, error) {
+	ev := newEvaluation(newGoValue(goRoot(v)), &e.opts)
+	ev.trackLocations = true
+	nodes := ev.evaluate(e.segments)
+	if ev.err != nil {
+		return nil, ev.err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	ptrs := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		g, ok := n.value.(*goValue)
+		if !ok || !g.slot.CanAddr() || !g.slot.Addr().CanInterface() {
+			return nil, fmt.Errorf("%w: %s", ErrNotAddressable, n.loc)
+		}
+		ptrs[i] = g.slot.Addr().Interface()
+	}
+	return ptrs, nil
+}
+
+// QueryValues evaluates path against a Go value, see Evaluator.EvaluateValues
+func QueryValues(v interface{}, path string) ([]interface{}, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.QueryValues(v)
+}
+
+// QueryPointers evaluates path against a Go value and returns pointers to the
+// selected values, see Evaluator.EvaluatePointers
+func QueryPointers(v interface{}, path string) ([]interface{}, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.QueryPointers(v)
+}
+
+// QueryValues evaluates the path against a Go value, see Evaluator.EvaluateValues
+func (p *Path) QueryValues(v interface{}) ([]interface{}, error) {
+	return p.eval.EvaluateValues(v)
+}
+
+// QueryPointers evaluates the path against a Go value and returns pointers to
+// the selected values, see Evaluator.EvaluatePointers
+func (p *Path) QueryPointers(v interface{}) ([]interface{}, error) {
+	return p.eval.EvaluatePointers(v)
+}
+
+// goRoot returns the slot holding the root value. The value a pointer points
+// to is used as the root so that $ itself is addressable.
+func goRoot(v interface{}) reflect.Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		return rv.Elem()
+	}
+	return rv
+}
+
+// interfaceOf returns the Go value held by v
+func interfaceOf(v Value) interface{} {
+	switch v := v.(type) {
+	case *goValue:
+		if v.slot.IsValid() && v.slot.CanInterface() {
+			return v.slot.Interface()
+		}
+		return nil
+	case jsonValue:
+		return v.r.PlainValue()
+	}
+	return nil
+}
+
+var (
+	numberType    = reflect.TypeOf(stdjson.Number(""))
+	marshalerType = reflect.TypeOf((*stdjson.Marshaler)(nil)).Elem()
+)
+
+// goValue is a Value backed by a Go value
+type goValue struct {
+	slot reflect.Value // where the value is stored, used to return pointers
+	v    reflect.Value // the value with pointers and interfaces removed
+	k    Kind
+	// json is the encoding of values implementing json.Marshaler and of
+	// fields with the ",string" option
+	json Value
+}
+
+// newGoValue resolves the value stored in slot. Nil pointers, interfaces,
+// maps and slices are null, as are values json.Marshal cannot encode.
+func newGoValue(slot reflect.Value) *goValue {
+	g := &goValue{slot: slot, k: KindNull}
+	v := slot
+	for v.IsValid() {
+		if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return g
+			}
+			if v.Kind() == reflect.Ptr && v.Type().Implements(marshalerType) {
+				break
+			}
+			v = v.Elem()
+			continue
+		}
+		break
+	}
+	if !v.IsValid() {
+		return g
+	}
+	g.v = v
+
+	if v.Type() != numberType && isMarshaler(v) {
+		g.json = marshalerValue(v)
+		if g.json != nil {
+			g.k = g.json.Kind()
+		}
+		return g
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		g.k = KindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		g.k = KindNumber
+	case reflect.String:
+		if v.Type() == numberType {
+			g.k = KindNumber
+		} else {
+			g.k = KindString
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return g
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			g.k = KindString // encoded as base64 like encoding/json
+		} else {
+			g.k = KindArray
+		}
+	case reflect.Array:
+		g.k = KindArray
+	case reflect.Map:
+		if !v.IsNil() {
+			g.k = KindObject
+		}
+	case reflect.Struct:
+		g.k = KindObject
+	}
+	return g
+}
+
+// isMarshaler reports whether json.Marshal would call MarshalJSON on v
+func isMarshaler(v reflect.Value) bool {
+	if v.Type().Implements(marshalerType) {
+		return true
+	}
+	return v.CanAddr() && reflect.PtrTo(v.Type()).Implements(marshalerType)
+}
+
+// marshalerValue encodes v with its MarshalJSON method
+func marshalerValue(v reflect.Value) Value {
+	if !v.Type().Implements(marshalerType) {
+		v = v.Addr()
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	raw, err := v.Interface().(stdjson.Marshaler).MarshalJSON()
+	if err != nil {
+		return nil
+	}
+	return newJSONValue(parseValue(string(raw)))
+}
+
+func (g *goValue) Kind() Kind {
+	return g.k
+}
+
+func (g *goValue) Member(name string) (Value, bool) {
+	if g.k != KindObject {
+		return nil, false
+	}
+	if g.json != nil {
+		return g.json.Member(name)
+	}
+
+	if g.v.Kind() == reflect.Struct {
+		for _, f := range cachedFields(g.v.Type()) {
+			if f.name == name {
+				fv, ok := fieldByIndex(g.v, f)
+				if !ok || f.omitEmpty && isEmptyValue(fv) {
+					return nil, false
+				}
+				return fieldValue(fv, f), true
+			}
+		}
+		return nil, false
+	}
+
+	keyType := g.v.Type().Key()
+	if keyType.Kind() == reflect.String {
+		mv := g.v.MapIndex(reflect.ValueOf(name).Convert(keyType))
+		if !mv.IsValid() {
+			return nil, false
+		}
+		return newGoValue(mv), true
+	}
+	for _, key := range g.v.MapKeys() {
+		if mapKey(key) == name {
+			return newGoValue(g.v.MapIndex(key)), true
+		}
+	}
+	return nil, false
+}
+
+func (g *goValue) Index(i int) (Value, bool) {
+	if g.k != KindArray {
+		return nil, false
+	}
+	if g.json != nil {
+		return g.json.Index(i)
+	}
+	if i < 0 || i >= g.v.Len() {
+		return nil, false
+	}
+	return newGoValue(g.v.Index(i)), true
+}
+
+func (g *goValue) Len() int {
+	if g.json != nil {
+		return g.json.Len()
+	}
+	switch g.k {
+	case KindArray:
+		return g.v.Len()
+	case KindObject:
+		if g.v.Kind() == reflect.Map {
+			return g.v.Len()
+		}
+		n := 0
+		g.Each(func(LocationStep, Value) bool {
+			n++
+			return true
+		})
+		return n
+	}
+	return 0
+}
+
+func (g *goValue) Each(fn func(step LocationStep, v Value) bool) {
+	if g.json != nil {
+		g.json.Each(fn)
+		return
+	}
+
+	switch g.k {
+	case KindArray:
+		for i := 0; i < g.v.Len(); i++ {
+			if !fn(indexStep(i), newGoValue(g.v.Index(i))) {
+				return
+			}
+		}
+	case KindObject:
+		if g.v.Kind() == reflect.Struct {
+			for _, f := range cachedFields(g.v.Type()) {
+				fv, ok := fieldByIndex(g.v, f)
+				if !ok || f.omitEmpty && isEmptyValue(fv) {
+					continue
+				}
+				if !fn(nameStep(f.name), fieldValue(fv, f)) {
+					return
+				}
+			}
+			return
+		}
+
+		// members are visited in key order like json.Marshal writes them
+		keys := g.v.MapKeys()
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = mapKey(key)
+		}
+		sort.Sort(byName{names, keys})
+		for i, key := range keys {
+			if !fn(nameStep(names[i]), newGoValue(g.v.MapIndex(key))) {
+				return
+			}
+		}
+	}
+}
+
+func (g *goValue) Bool() bool {
+	if g.json != nil {
+		return g.json.Bool()
+	}
+	return g.k == KindBool && g.v.Bool()
+}
+
+func (g *goValue) Number() float64 {
+	if g.json != nil {
+		return g.json.Number()
+	}
+	if g.k != KindNumber {
+		return 0
+	}
+	switch g.v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(g.v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(g.v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return g.v.Float()
+	case reflect.String:
+		f, _ := strconv.ParseFloat(g.v.String(), 64)
+		return f
+	}
+	return 0
+}
+
+func (g *goValue) Str() string {
+	if g.json != nil {
+		return g.json.Str()
+	}
+	if g.k != KindString {
+		return ""
+	}
+	if g.v.Kind() == reflect.Slice {
+		return base64.StdEncoding.EncodeToString(g.v.Bytes())
+	}
+	return g.v.String()
+}
+
+// result encodes the value as JSON
+func (g *goValue) result() Result {
+	if g.json != nil {
+		return valueResult(g.json)
+	}
+	if g.k == KindNull || !g.slot.CanInterface() {
+		return Result{Type: JSONTypeNull, Raw: "null"}
+	}
+	raw, err := marshalValue(g.slot.Interface())
+	if err != nil {
+		return Result{Type: JSONTypeNull, Raw: "null"}
+	}
+	return parseValue(raw)
+}
+
+// mapKey returns the object member name of a map key
+func mapKey(key reflect.Value) string {
+	switch key.Kind() {
+	case reflect.String:
+		return key.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10)
+	}
+	if key.CanInterface() {
+		return fmt.Sprint(key.Interface())
+	}
+	return ""
+}
+
+// byName sorts map keys by their member names
+type byName struct {
+	names []string
+	keys  []reflect.Value
+}
+
+func (s byName) Len() int           { return len(s.names) }
+func (s byName) Less(i, j int) bool { return s.names[i] < s.names[j] }
+func (s byName) Swap(i, j int) {
+	s.names[i], s.names[j] = s.names[j], s.names[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// goField is a struct field encoded as an object member
+type goField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	// quoted is set by the ",string" option on a bool, number or string field
+	quoted bool
+}
+
+// candidate is a field found at depth levels of embedding
+type candidate struct {
+	goField
+	depth int
+}
+
+var fieldCache sync.Map // map[reflect.Type][]goField
+
+// cachedFields returns the fields of struct type t in the order json.Marshal writes them
+func cachedFields(t reflect.Type) []goField {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]goField)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return f.([]goField)
+}
+
+// typeFields lists the exported fields of t, including those promoted from
+// embedded structs. Like encoding/json, a shallower field hides deeper ones
+// with the same name, a tagged field wins at the same depth, and other
+// conflicts hide every field with that name.
+func typeFields(t reflect.Type) []goField {
+	var candidates []candidate
+
+	var walk func(t reflect.Type, index []int, depth int, visited map[reflect.Type]bool)
+	walk = func(t reflect.Type, index []int, depth int, visited map[reflect.Type]bool) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		defer delete(visited, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.Anonymous {
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.PkgPath != "" && (sf.Type.Kind() == reflect.Ptr || ft.Kind() != reflect.Struct) {
+					continue // embedded pointers to unexported structs are ignored
+				}
+			} else if sf.PkgPath != "" {
+				continue // unexported
+			}
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts := tag, ""
+			if j := strings.Index(tag, ","); j >= 0 {
+				name, opts = tag[:j], tag[j+1:]
+			}
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				walk(ft, fieldIndex, depth+1, visited)
+				continue
+			}
+
+			f := candidate{
+				goField: goField{
+					name:   name,
+					index:  fieldIndex,
+					tagged: name != "",
+				},
+				depth: depth,
+			}
+			if f.name == "" {
+				f.name = sf.Name
+			}
+			for _, opt := range strings.Split(opts, ",") {
+				switch opt {
+				case "omitempty":
+					f.omitEmpty = true
+				case "string":
+					f.quoted = isQuotable(sf.Type)
+				}
+			}
+			candidates = append(candidates, f)
+		}
+	}
+	walk(t, nil, 0, map[reflect.Type]bool{})
+
+	// keep the dominant field of every name
+	byName := make(map[string][]candidate)
+	for _, c := range candidates {
+		byName[c.name] = append(byName[c.name], c)
+	}
+	var fields []goField
+	for _, group := range byName {
+		if f, ok := dominantField(group); ok {
+			fields = append(fields, f)
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return lessIndex(fields[i].index, fields[j].index)
+	})
+	return fields
+}
+
+// dominantField picks the field that hides the others with the same name
+func dominantField(group []candidate) (goField, bool) {
+	minDepth := group[0].depth
+	for _, c := range group {
+		if c.depth < minDepth {
+			minDepth = c.depth
+		}
+	}
+	var shallow, tagged []goField
+	for _, c := range group {
+		if c.depth != minDepth {
+			continue
+		}
+		shallow = append(shallow, c.goField)
+		if c.tagged {
+			tagged = append(tagged, c.goField)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	if len(tagged) == 0 && len(shallow) == 1 {
+		return shallow[0], true
+	}
+	return goField{}, false
+}
+
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// isQuotable reports whether the ",string" option applies to a field of type t
+func isQuotable(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// fieldValue returns the value of the struct field f stored in fv. A field
+// with the ",string" option is a string holding its JSON encoding, as
+// json.Marshal writes it; EvaluateValues and EvaluatePointers still return
+// the Go field itself.
+func fieldValue(fv reflect.Value, f goField) *goValue {
+	g := newGoValue(fv)
+	if !f.quoted || g.json != nil || g.k == KindNull || !g.v.CanInterface() {
+		return g
+	}
+	raw, err := marshalValue(g.v.Interface())
+	if err != nil {
+		return g
+	}
+	g.json = jsonValue{r: parseValue(quoteString(raw))}
+	g.k = KindString
+	return g
+}
+
+// fieldByIndex returns the field f of struct v, or false if it is inside a nil embedded pointer
+func fieldByIndex(v reflect.Value, f goField) (reflect.Value, bool) {
+	for i, x := range f.index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// isEmptyValue reports whether omitempty leaves v out
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package jsonpath
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type goTestBase struct {
+	ID      int    `json:"id"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type goTestBook struct {
+	goTestBase
+	Title    string            `json:"title"`
+	Price    float64           `json:"price"`
+	Tags     []string          `json:"tags"`
+	Author   *goTestAuthor     `json:"author,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Internal string            `json:"-"`
+	Released time.Time         `json:"released"`
+	note     string
+}
+
+type goTestAuthor struct {
+	Name string
+}
+
+func TestQueryValues(t *testing.T) {
+	var decoded interface{}
+	d := stdjson.NewDecoder(strings.NewReader(`{"a": [1, 2.5, {"b": "x"}], "c": {"d": null, "e": true}}`))
+	d.UseNumber()
+	if err := d.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	released := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	books := []goTestBook{
+		{goTestBase: goTestBase{ID: 1}, Title: "A", Price: 8, Tags: []string{"x"}, Internal: "i", Released: released, note: "n"},
+		{goTestBase: goTestBase{ID: 2, Comment: "c"}, Title: "B", Price: 20, Author: &goTestAuthor{Name: "Bob"}, Meta: map[string]string{"z": "1", "y": "2"}},
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		path string
+		want []interface{}
+	}{
+		{"解码后的值", decoded, "$.a[0]", []interface{}{stdjson.Number("1")}},
+		{"json.Number 过滤", decoded, "$.a[?@ > 2]", []interface{}{stdjson.Number("2.5")}},
+		{"嵌套成员", decoded, "$..b", []interface{}{"x"}},
+		{"null 成员", decoded, "$.c[?@ == null]", []interface{}{nil}},
+		{"布尔过滤", decoded, "$.c[?@ == true]", []interface{}{true}},
+		{"结构体字段", books, "$[*].title", []interface{}{"A", "B"}},
+		{"嵌入字段", books, "$[*].id", []interface{}{1, 2}},
+		{"omitempty", books, "$[*].comment", []interface{}{"c"}},
+		{"忽略的字段", books, "$[*].Internal", nil},
+		{"未导出字段", books, "$[*].note", nil},
+		{"指针字段", books, "$[1].author.Name", []interface{}{"Bob"}},
+		{"过滤结构体", books, "$[?@.price < 10].title", []interface{}{"A"}},
+		{"map 按键排序", books, "$[1].meta.*", []interface{}{"2", "1"}},
+		{"json.Marshaler", books, "$[0].released", []interface{}{released}},
+		{"函数", books, "$[?length(@.tags) == 1].title", []interface{}{"A"}},
+		{"比较数组", books, `$[?@.tags == $[0].tags].id`, []interface{}{1}},
+		{"通配符", map[string]int{"b": 2, "a": 1}, "$.*", []interface{}{1, 2}},
+		{"数组", [3]int{1, 2, 3}, "$[-1]", []interface{}{3}},
+		{"无匹配", books, "$.nothing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryValues(tt.v, tt.path)
+			if err != nil {
+				t.Fatalf("QueryValues() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryValues() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryValues_Modify(t *testing.T) {
+	doc := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"n": 1},
+			map[string]interface{}{"n": 2},
+		},
+	}
+	items, err := QueryValues(doc, "$.items[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		item.(map[string]interface{})["seen"] = true
+	}
+	for _, item := range doc["items"].([]interface{}) {
+		if item.(map[string]interface{})["seen"] != true {
+			t.Errorf("QueryValues() did not return the stored map %v", item)
+		}
+	}
+}
+
+func TestQueryPointers(t *testing.T) {
+	books := []goTestBook{{Title: "A", Price: 8}, {Title: "B", Price: 20}}
+	ptrs, err := QueryPointers(books, "$[?@.price > 10].price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptrs) != 1 {
+		t.Fatalf("QueryPointers() = %v, want 1 pointer", ptrs)
+	}
+	*ptrs[0].(*float64) = 10
+	if books[1].Price != 10 {
+		t.Errorf("Price = %v, want 10", books[1].Price)
+	}
+
+	doc := map[string]interface{}{"a": []interface{}{1, "x"}}
+	ptrs, err = QueryPointers(doc, "$.a[1]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	*ptrs[0].(*interface{}) = "y"
+	if doc["a"].([]interface{})[1] != "y" {
+		t.Errorf("doc = %v", doc)
+	}
+
+	book := goTestBook{Title: "A"}
+	ptrs, err = QueryPointers(&book, "$")
+	if err != nil || ptrs[0] != &book {
+		t.Errorf("QueryPointers($) = %v, %v", ptrs, err)
+	}
+
+	if _, err := QueryPointers(doc, "$.a"); !errors.Is(err, ErrNotAddressable) {
+		t.Errorf("QueryPointers() of map value error = %v, want ErrNotAddressable", err)
+	}
+	if _, err := QueryPointers(book, "$.title"); !errors.Is(err, ErrNotAddressable) {
+		t.Errorf("QueryPointers() of struct passed by value error = %v, want ErrNotAddressable", err)
+	}
+	if _, err := QueryPointers(doc, "$["); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("QueryPointers() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestTypeFields_Conflicts(t *testing.T) {
+	type A struct{ Name string }
+	type B struct{ Name string }
+	type C struct {
+		Name string `json:"Name"`
+	}
+	type conflict struct {
+		A
+		B
+	}
+	type tagged struct {
+		A
+		C
+	}
+	type shallow struct {
+		A
+		Name int
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want []interface{}
+	}{
+		{"同层冲突", conflict{A{"a"}, B{"b"}}, nil},
+		{"带标签优先", tagged{A{"a"}, C{"c"}}, []interface{}{"c"}},
+		{"浅层优先", shallow{A{"a"}, 1}, []interface{}{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryValues(tt.v, "$.Name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryValues() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryValues_StringOption(t *testing.T) {
+	type item struct {
+		Name string  `json:"name"`
+		B    int     `json:"b,string"`
+		S    string  `json:"s,string"`
+		P    *bool   `json:"p,string"`
+		T    []int   `json:"t,string"`
+		N    *uint32 `json:"n,string"`
+	}
+	yes := true
+	items := []item{{Name: "x", B: 3, S: "x", P: &yes, T: []int{1}}}
+
+	tests := []struct {
+		name string
+		path string
+		want []interface{}
+	}{
+		{"数字按字符串比较", `$[?@.b == '3'].name`, []interface{}{"x"}},
+		{"数字不再是数字", `$[?@.b == 3].name`, nil},
+		{"字符串再次编码", `$[?@.s == '"x"'].name`, []interface{}{"x"}},
+		{"指针", `$[?@.p == 'true'].name`, []interface{}{"x"}},
+		{"不适用的类型", `$[?@.t[0] == 1].name`, []interface{}{"x"}},
+		{"空指针仍为 null", `$[?@.n == null].name`, []interface{}{"x"}},
+		{"返回 Go 值", `$[*].b`, []interface{}{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := QueryValues(items, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryValues() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	raw, _ := stdjson.Marshal(items[0])
+	if got := Get(string(raw), "$.b"); got.Str != "3" {
+		t.Errorf("json.Marshal wrote %s", raw)
+	}
+}
@@ -0,0 +1,138 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIndexOutOfRange is reported by Insert when the index is outside an array
+var ErrIndexOutOfRange = errors.New("jsonpath: index out of range")
+
+// Append appends value, encoded with encoding/json, to every array selected by
+// path and returns the rewritten document. Pass a json.RawMessage to append
+// raw JSON.
+//
+// If path selects a value that is not an array, no change is made and an
+// error wrapping ErrNotContainer is returned. Arrays selected more than once
+// are appended to once.
+func Append(json, path string, value interface{}) (string, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return "", err
+	}
+	return p.Append(json, value)
+}
+
+// Insert inserts value, encoded with encoding/json, at index into every array
+// selected by path and returns the rewritten document. The value is inserted
+// before the element at index; an index equal to the array length appends. A
+// negative index counts back from the array length, so -1 inserts before the
+// last element.
+//
+// If path selects a value that is not an array, an error wrapping
+// ErrNotContainer is returned; if index is out of range for any selected
+// array, an error wrapping ErrIndexOutOfRange is returned. In both cases no
+// change is made.
+func Insert(json, path string, index int, value interface{}) (string, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return "", err
+	}
+	return p.Insert(json, index, value)
+}
+
+// AddMember adds the member key with value, encoded with encoding/json, to
+// every object selected by path that does not have it yet, and returns the
+// rewritten document. Existing members are never overwritten.
+//
+// If path selects a value that is not an object, no change is made and an
+// error wrapping ErrNotContainer is returned.
+func AddMember(json, path, key string, value interface{}) (string, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return "", err
+	}
+	return p.AddMember(json, key, value)
+}
+
+// Append appends value to every array selected by the path, see Append
+func (p *Path) Append(json string, value interface{}) (string, error) {
+	return p.editContainers(json, value, func(n Node, raw string) (edit, bool, error) {
+		if !n.Value.IsArray() {
+			return edit{}, false, fmt.Errorf("%w: %s is not an array", ErrNotContainer, n.Location)
+		}
+		children := childSpans(json, n.Value)
+		return appendChildEdit(json, n, children, containerLayout(json, n, children), raw), true, nil
+	})
+}
+
+// Insert inserts value at index into every array selected by the path, see Insert
+func (p *Path) Insert(json string, index int, value interface{}) (string, error) {
+	return p.editContainers(json, value, func(n Node, raw string) (edit, bool, error) {
+		if !n.Value.IsArray() {
+			return edit{}, false, fmt.Errorf("%w: %s is not an array", ErrNotContainer, n.Location)
+		}
+		children := childSpans(json, n.Value)
+		i := index
+		if i < 0 {
+			i += len(children)
+		}
+		if i < 0 || i > len(children) {
+			return edit{}, false, fmt.Errorf("%w: %d for %s of length %d", ErrIndexOutOfRange, index, n.Location, len(children))
+		}
+		l := containerLayout(json, n, children)
+		if i == len(children) {
+			return appendChildEdit(json, n, children, l, raw), true, nil
+		}
+		return edit{start: children[i].start, end: children[i].start, text: raw + l.sep}, true, nil
+	})
+}
+
+// AddMember adds a member to every object selected by the path, see AddMember
+func (p *Path) AddMember(json, key string, value interface{}) (string, error) {
+	return p.editContainers(json, value, func(n Node, raw string) (edit, bool, error) {
+		if !n.Value.IsObject() {
+			return edit{}, false, fmt.Errorf("%w: %s is not an object", ErrNotContainer, n.Location)
+		}
+		if _, exists := n.Value.member(key); exists {
+			return edit{}, false, nil
+		}
+		children := childSpans(json, n.Value)
+		l := containerLayout(json, n, children)
+		return appendChildEdit(json, n, children, l, l.member(key, raw)), true, nil
+	})
+}
+
+// containerEditFunc returns the edit to apply to a selected container, or
+// false to leave it unchanged
+type containerEditFunc func(n Node, raw string) (edit, bool, error)
+
+// editContainers encodes value and applies fn to every distinct node selected
+// by the path. No change is made if fn fails for any node.
+func (p *Path) editContainers(json string, value interface{}, fn containerEditFunc) (string, error) {
+	raw, err := marshalValue(value)
+	if err != nil {
+		return "", err
+	}
+	nodes, err := p.eval.evaluateNodes(json)
+	if err != nil {
+		return "", err
+	}
+
+	var edits []edit
+	seen := make(map[int]bool, len(nodes))
+	for _, n := range nodes {
+		if seen[n.Value.Index] {
+			continue
+		}
+		seen[n.Value.Index] = true
+		e, ok, err := fn(n, raw)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			edits = append(edits, e)
+		}
+	}
+	return applyEdits(json, edits), nil
+}
@@ -0,0 +1,106 @@
+package jsonpath
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		path    string
+		value   interface{}
+		want    string
+		wantErr error
+	}{
+		{"追加元素", `{"a": [1, 2]}`, "$.a", 3, `{"a": [1, 2, 3]}`, nil},
+		{"空数组", `{"a": []}`, "$.a", 1, `{"a": [1]}`, nil},
+		{"空白数组", `{"a": [ ]}`, "$.a", 1, `{"a": [1 ]}`, nil},
+		{"多个数组", `{"a": [[], [1]]}`, "$.a[*]", "x", `{"a": [["x"], [1, "x"]]}`, nil},
+		{"嵌套数组", `{"a": [[1]]}`, "$..[?@[0]]", 2, `{"a": [[1, 2], 2]}`, nil},
+		{"后代数组", `{"a": [[1]]}`, "$..*", nil, "", ErrNotContainer},
+		{"根数组", "[1]\n", "$", 2, "[1,2]\n", nil},
+		{"原始 JSON", `[]`, "$", stdjson.RawMessage(`{"k": true}`), `[{"k":true}]`, nil},
+		{"重复选择只追加一次", `{"a": []}`, "$['a','a']", 1, `{"a": [1]}`, nil},
+		{"非数组", `{"a": {}}`, "$.a", 1, "", ErrNotContainer},
+		{"无匹配", `{"a": []}`, "$.b", 1, `{"a": []}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Append(tt.json, tt.path, tt.value)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("Append(%q) error = %v, want %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Append(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		index   int
+		want    string
+		wantErr error
+	}{
+		{"插入开头", `[1, 2]`, 0, `[0, 1, 2]`, nil},
+		{"插入中间", `[1, 2]`, 1, `[1, 0, 2]`, nil},
+		{"插入末尾", `[1, 2]`, 2, `[1, 2, 0]`, nil},
+		{"负索引", `[1, 2]`, -1, `[1, 0, 2]`, nil},
+		{"空数组", `[]`, 0, `[0]`, nil},
+		{"越界", `[1, 2]`, 3, "", ErrIndexOutOfRange},
+		{"负索引越界", `[1, 2]`, -3, "", ErrIndexOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Insert(tt.json, "$", tt.index, 0)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("Insert(%d) error = %v, want %v", tt.index, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Insert(%d) = %s, want %s", tt.index, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Insert(`{"a": 1}`, "$.a", 0, 0); !errors.Is(err, ErrNotContainer) {
+		t.Errorf("Insert() error = %v, want ErrNotContainer", err)
+	}
+}
+
+func TestAddMember(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{"添加成员", `{"a": {"x": 1}}`, "$.a", `{"a": {"x": 1, "k": "v"}}`, nil},
+		{"空对象", `{"a": {}}`, "$.a", `{"a": {"k": "v"}}`, nil},
+		{"不覆盖已有成员", `{"a": {"k": 1}}`, "$.a", `{"a": {"k": 1}}`, nil},
+		{"空名成员之后的已有成员", `{"a": {"": 1, "k": 2}}`, "$.a", `{"a": {"": 1, "k": 2}}`, nil},
+		{"每个匹配的对象", `{"s": [{"k": 0}, {}, {"x": 1}]}`, "$.s[*]", `{"s": [{"k": 0}, {"k": "v"}, {"x": 1, "k": "v"}]}`, nil},
+		{"根对象", `{}`, "$", `{"k":"v"}`, nil},
+		{"非对象", `{"a": []}`, "$.a", "", ErrNotContainer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AddMember(tt.json, tt.path, "k", "v")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Fatalf("AddMember(%q) error = %v, want %v", tt.path, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AddMember(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
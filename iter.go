@@ -0,0 +1,54 @@
+//go:build go1.23
+
+package jsonpath
+
+import "iter"
+
+// All returns an iterator over the results of path in json. Results are
+// produced while the document is walked, and breaking out of the loop stops
+// the walk. Errors are ignored as by GetMany; use ForEachMatch to inspect them.
+func All(json, path string) iter.Seq[Result] {
+	p, err := compileCached(path)
+	if err != nil {
+		return func(func(Result) bool) {}
+	}
+	return p.All(json)
+}
+
+// AllNodes returns an iterator over the locations and results of path in json
+func AllNodes(json, path string) iter.Seq2[Location, Result] {
+	p, err := compileCached(path)
+	if err != nil {
+		return func(func(Location, Result) bool) {}
+	}
+	return p.AllNodes(json)
+}
+
+// All returns an iterator over the results of the path in json
+func (p *Path) All(json string) iter.Seq[Result] {
+	return func(yield func(Result) bool) {
+		p.eval.forEach(json, yield)
+	}
+}
+
+// AllNodes returns an iterator over the locations and results of the path in json
+func (p *Path) AllNodes(json string) iter.Seq2[Location, Result] {
+	return func(yield func(Location, Result) bool) {
+		p.eval.forEachNode(json, func(n Node) bool {
+			return yield(n.Location, n.Value)
+		})
+	}
+}
+
+// Elements returns an iterator over the elements of an array. Elements are
+// scanned one at a time instead of being collected like Array does. Other
+// values yield nothing.
+func (r Result) Elements() iter.Seq[Result] {
+	return r.forEachElement
+}
+
+// Members returns an iterator over the names and values of the members of an
+// object in document order. Other values yield nothing.
+func (r Result) Members() iter.Seq2[string, Result] {
+	return r.forEachMember
+}
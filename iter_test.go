@@ -0,0 +1,76 @@
+//go:build go1.23
+
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	json := `{"a": [{"b": 1}, {"b": 2}, {"b": 3}]}`
+
+	var got []int64
+	for r := range All(json, "$.a[*].b") {
+		got = append(got, r.Int())
+		if len(got) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(got, []int64{1, 2}) {
+		t.Errorf("All() = %v, want [1 2]", got)
+	}
+
+	var locs []string
+	for loc, r := range AllNodes(json, "$..b") {
+		locs = append(locs, loc.String()+"="+r.Raw)
+	}
+	want := []string{"$['a'][0]['b']=1", "$['a'][1]['b']=2", "$['a'][2]['b']=3"}
+	if !reflect.DeepEqual(locs, want) {
+		t.Errorf("AllNodes() = %v, want %v", locs, want)
+	}
+
+	for range All(json, "$[") {
+		t.Error("All() of an invalid path yielded a result")
+	}
+}
+
+func TestResult_Elements(t *testing.T) {
+	r := Get(`{"a": [1, "x", [2]]}`, "$.a")
+
+	var got []string
+	for elem := range r.Elements() {
+		got = append(got, elem.Raw)
+	}
+	if !reflect.DeepEqual(got, []string{"1", `"x"`, "[2]"}) {
+		t.Errorf("Elements() = %v", got)
+	}
+
+	for elem := range r.Elements() {
+		if elem.Raw != "1" {
+			t.Errorf("first element = %s, want 1", elem.Raw)
+		}
+		break
+	}
+
+	for range Get(`{"a": 1}`, "$.a").Elements() {
+		t.Error("Elements() of a number yielded a value")
+	}
+}
+
+func TestResult_Members(t *testing.T) {
+	r := Get(`{"o": {"b": 1, "a": {"c": 2}, "d!": 3}}`, "$.o")
+
+	var keys []string
+	for key, value := range r.Members() {
+		keys = append(keys, key+"="+value.Raw)
+	}
+	want := []string{"b=1", `a={"c": 2}`, "d!=3"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Members() = %v, want %v", keys, want)
+	}
+
+	for range Get(`[1]`, "$").Members() {
+		t.Error("Members() of an array yielded a value")
+	}
+}
@@ -288,6 +288,49 @@ func parseObjectMember(json string, i int) (string, Result, int) {
 	return key, value, nextPos
 }
 
+// scanStringJSON returns the end of the string starting at json[i] and
+// whether it contains escape sequences
+func scanStringJSON(json string, i int) (int, bool) {
+	escaped := false
+	for j := i + 1; j < len(json); j++ {
+		switch json[j] {
+		case '\\':
+			escaped = true
+			j++
+		case '"':
+			return j + 1, escaped
+		}
+	}
+	return len(json), escaped
+}
+
+// skipValueJSON returns the end of the value starting at json[i] without
+// decoding it, or i if no value starts there
+func skipValueJSON(json string, i int) int {
+	if i >= len(json) {
+		return i
+	}
+	switch json[i] {
+	case '"':
+		end, _ := scanStringJSON(json, i)
+		return end
+	case '{':
+		return i + len(squashJSONObject(json[i:]))
+	case '[':
+		return i + len(squashJSONArray(json[i:]))
+	case 'n', 't', 'f':
+		return i + len(tolit(json[i:]))
+	case '+', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		// same termination as tonum
+		j := i + 1
+		for j < len(json) && json[j] > ' ' && json[j] != ',' && json[j] != ']' && json[j] != '}' {
+			j++
+		}
+		return j
+	}
+	return i
+}
+
 // squashJSONArray extracts a complete JSON array
 func squashJSONArray(json string) string {
 	depth := 0
@@ -307,7 +350,7 @@ func squashJSONArray(json string) string {
 						}
 						n++
 					}
-					if n%2 == 0 {
+					if n%2 == 1 {
 						break
 					}
 				}
@@ -343,7 +386,7 @@ func squashJSONObject(json string) string {
 						}
 						n++
 					}
-					if n%2 == 0 {
+					if n%2 == 1 {
 						break
 					}
 				}
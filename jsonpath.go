@@ -4,6 +4,7 @@
 package jsonpath
 
 import (
+	"context"
 	"strconv"
 	"strings"
 )
@@ -41,22 +42,18 @@ type Result struct {
 	Str string
 	// Num is the json number
 	Num float64
-	// Index of raw value in original json, zero means index unknown
+	// Index of raw value in original json, zero means index unknown.
+	// It is set for the root value and for every value reached from it.
 	Index int
 }
 
 // Get executes a JSONPath query and returns the first result
 func Get(json, path string) Result {
-	query, err := Parse(path)
+	p, err := compileCached(path)
 	if err != nil {
 		return Result{}
 	}
-	eval := NewEvaluator(json, query)
-	results := eval.Evaluate()
-	if len(results) == 0 {
-		return Result{}
-	}
-	return results[0]
+	return p.Get(json)
 }
 
 // GetBytes executes a JSONPath query with []byte input
@@ -74,12 +71,11 @@ func (r Result) Get(path string) Result {
 
 // GetMany executes a JSONPath query and returns all results
 func GetMany(json, path string) []Result {
-	query, err := Parse(path)
+	p, err := compileCached(path)
 	if err != nil {
 		return nil
 	}
-	eval := NewEvaluator(json, query)
-	return eval.Evaluate()
+	return p.GetMany(json)
 }
 
 // GetManyBytes executes a JSONPath query with []byte input
@@ -95,6 +91,61 @@ func (r Result) GetMany(path string) []Result {
 	return GetMany(r.Raw, path)
 }
 
+// QueryAll executes a JSONPath query and returns all results.
+//
+// Unlike GetMany, QueryAll reports errors: an invalid path (ErrInvalidPath),
+// malformed JSON (ErrInvalidJSON) and function extensions that cannot be
+// evaluated (ErrFunction). Use errors.As with *PathError, *JSONError or
+// *FunctionError for details.
+func QueryAll(json, path string) ([]Result, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.QueryAll(json)
+}
+
+// QueryContext is like QueryAll but stops evaluating once ctx is done and
+// returns ctx.Err()
+func QueryContext(ctx context.Context, json, path string) ([]Result, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.QueryContext(ctx, json)
+}
+
+// QueryOne executes a JSONPath query and returns the first result.
+// A query without matches returns an empty Result and a nil error.
+func QueryOne(json, path string) (Result, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return Result{}, err
+	}
+	return p.QueryOne(json)
+}
+
+// ForEachMatch executes a JSONPath query and calls fn for every result as soon
+// as it is found, stopping when fn returns false. Errors are reported as by
+// QueryAll.
+func ForEachMatch(json, path string, fn func(r Result) bool) error {
+	p, err := compileCached(path)
+	if err != nil {
+		return err
+	}
+	return p.ForEachMatch(json, fn)
+}
+
+// QueryNodes executes a JSONPath query and returns all selected nodes together
+// with their locations. Errors are reported as by QueryAll.
+func QueryNodes(json, path string) ([]Node, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.QueryNodes(json)
+}
+
 // Exists checks if the result exists
 func (r Result) Exists() bool {
 	return r.Type != JSONTypeNull || len(r.Raw) != 0
@@ -211,6 +262,19 @@ func (r Result) Array() []Result {
 	}
 
 	var results []Result
+	r.forEachElement(func(elem Result) bool {
+		results = append(results, elem)
+		return true
+	})
+	return results
+}
+
+// forEachElement scans the elements of an array one at a time, stopping when
+// fn returns false
+func (r Result) forEachElement(fn func(elem Result) bool) {
+	if !r.IsArray() {
+		return
+	}
 	i := 1
 	for i < len(r.Raw) {
 		i = skipWhitespaceJSON(r.Raw, i)
@@ -218,7 +282,14 @@ func (r Result) Array() []Result {
 			break
 		}
 		elem, next := parseArrayElement(r.Raw, i)
-		results = append(results, elem)
+		// Stop parsing on invalid JSON to prevent infinite loop
+		if next == i {
+			break
+		}
+		elem.Index = r.Index + i
+		if !fn(elem) {
+			return
+		}
 		i = next
 
 		i = skipWhitespaceJSON(r.Raw, i)
@@ -226,7 +297,6 @@ func (r Result) Array() []Result {
 			i++
 		}
 	}
-	return results
 }
 
 // Map returns the map[string]Result representation
@@ -252,6 +322,137 @@ func (r Result) MapKVList() []KV {
 	}
 
 	var results []KV
+	r.forEachMember(func(key string, value Result) bool {
+		results = append(results, KV{Key: key, Value: value})
+		return true
+	})
+	return results
+}
+
+// member returns the value of the member named name, the last one if the
+// name is repeated, as Map does. Keeping last-wins means the lookup does not
+// stop at the first matching key: the whole object is scanned and only the
+// last match is parsed. The scan is in place: keys are compared without
+// unescaping unless they contain escape sequences, and values are skipped
+// structurally.
+func (r Result) member(name string) (Result, bool) {
+	if !r.IsObject() {
+		return Result{}, false
+	}
+	json := r.Raw
+	found := -1
+	for i := 1; ; i++ {
+		i = skipWhitespaceJSON(json, i)
+		if i >= len(json) || json[i] != '"' {
+			break
+		}
+		keyEnd, escaped := scanStringJSON(json, i)
+		var match bool
+		if escaped {
+			_, key := tostr(json[i:keyEnd])
+			match = key == name
+		} else {
+			match = keyEnd-i-2 == len(name) && json[i+1:keyEnd-1] == name
+		}
+
+		i = skipWhitespaceJSON(json, keyEnd)
+		if i >= len(json) || json[i] != ':' {
+			break
+		}
+		i = skipWhitespaceJSON(json, i+1)
+
+		next := skipValueJSON(json, i)
+		if next == i {
+			break
+		}
+		if match {
+			found = i
+		}
+		i = skipWhitespaceJSON(json, next)
+		if i >= len(json) || json[i] != ',' {
+			break
+		}
+	}
+
+	if found < 0 {
+		return Result{}, false
+	}
+	value, _ := parseArrayElement(json, found)
+	value.Index = r.Index + found
+	return value, true
+}
+
+// forEachElementOffset scans the elements of an array without parsing them,
+// calling fn with the position and offset of each one until fn returns false
+func (r Result) forEachElementOffset(fn func(i, offset int) bool) {
+	if !r.IsArray() {
+		return
+	}
+	json := r.Raw
+	j := 1
+	for i := 0; ; i++ {
+		j = skipWhitespaceJSON(json, j)
+		if j >= len(json) || json[j] == ']' {
+			return
+		}
+		next := skipValueJSON(json, j)
+		// Stop on invalid JSON, like forEachElement
+		if next == j || !fn(i, j) {
+			return
+		}
+		j = skipWhitespaceJSON(json, next)
+		if j < len(json) && json[j] == ',' {
+			j++
+		}
+	}
+}
+
+// elementAt parses the array element starting at offset
+func (r Result) elementAt(offset int) (Result, bool) {
+	elem, next := parseArrayElement(r.Raw, offset)
+	if next == offset {
+		return Result{}, false
+	}
+	elem.Index = r.Index + offset
+	return elem, true
+}
+
+// element returns the element at index i of an array, skipping the elements
+// before it without parsing them
+func (r Result) element(i int) (Result, bool) {
+	if i < 0 {
+		return Result{}, false
+	}
+	offset := -1
+	r.forEachElementOffset(func(n, off int) bool {
+		if n == i {
+			offset = off
+			return false
+		}
+		return true
+	})
+	if offset < 0 {
+		return Result{}, false
+	}
+	return r.elementAt(offset)
+}
+
+// elementCount returns the number of elements of an array without parsing them
+func (r Result) elementCount() int {
+	count := 0
+	r.forEachElementOffset(func(int, int) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+// forEachMember scans the members of an object one at a time, stopping when
+// fn returns false
+func (r Result) forEachMember(fn func(key string, value Result) bool) {
+	if !r.IsObject() {
+		return
+	}
 	i := 1
 	for i < len(r.Raw) {
 		i = skipWhitespaceJSON(r.Raw, i)
@@ -259,11 +460,15 @@ func (r Result) MapKVList() []KV {
 			break
 		}
 		key, value, next := parseObjectMember(r.Raw, i)
-		// Stop parsing on invalid JSON to prevent infinite loop
-		if key == "" {
+		// Stop parsing on invalid JSON to prevent infinite loop. An empty
+		// key is a valid member name.
+		if next == i || !value.Exists() {
 			break
 		}
-		results = append(results, KV{Key: key, Value: value})
+		value.Index = r.Index + next - len(value.Raw)
+		if !fn(key, value) {
+			return
+		}
 		i = next
 
 		i = skipWhitespaceJSON(r.Raw, i)
@@ -271,7 +476,6 @@ func (r Result) MapKVList() []KV {
 			i++
 		}
 	}
-	return results
 }
 
 // Value returns the Go native value representation
@@ -1,6 +1,12 @@
 package jsonpath
 
-import "testing"
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestResult_Array(t *testing.T) {
 	tests := []struct {
@@ -49,3 +55,226 @@ func TestResult_Map(t *testing.T) {
 		})
 	}
 }
+
+func TestResult_ArrayInvalid(t *testing.T) {
+	// must not loop forever on values the lenient parser does not understand
+	r := parseValue(`[1, NaN, 2]`)
+	if got := r.Array(); len(got) != 1 {
+		t.Errorf("Array() len = %d, want 1", len(got))
+	}
+}
+
+func TestResult_member(t *testing.T) {
+	tests := []struct {
+		name  string
+		json  string
+		key   string
+		want  string
+		found bool
+	}{
+		{"简单成员", `{"a": 1, "b": 2}`, "b", "2", true},
+		{"不存在", `{"a": 1}`, "b", "", false},
+		{"跳过复杂值", `{"a": {"x": "}]\"{"}, "s": "a,\"b\"", "n": -1.5e3, "t": true, "b": [1]}`, "b", "[1]", true},
+		{"键含转义", `{"a\"b": 1, "c": 2}`, "c", "2", true},
+		{"转义键完整比较", `{"a\nb": 1}`, "a\nb", "1", true},
+		{"前缀不匹配", `{"ab": 1, "a": 2}`, "a", "2", true},
+		{"空键", `{"": 3}`, "", "3", true},
+		{"重复键取最后一个", `{"a": 1, "a": 2}`, "a", "2", true},
+		{"空对象", `{}`, "a", "", false},
+		{"非对象", `[1]`, "0", "", false},
+		{"截断的对象", `{"a": 1, "b"`, "b", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := parseValue(tt.json)
+			got, ok := r.member(tt.key)
+			if ok != tt.found || got.Raw != tt.want {
+				t.Fatalf("member(%q) = %q, %v, want %q, %v", tt.key, got.Raw, ok, tt.want, tt.found)
+			}
+			if ok && tt.json[got.Index:got.Index+len(got.Raw)] != got.Raw {
+				t.Errorf("member(%q).Index = %d does not point to the value", tt.key, got.Index)
+			}
+		})
+	}
+}
+
+func TestEmptyMemberNames(t *testing.T) {
+	json := `{"": 1, "b": {"": 2, "c": 3}}`
+	doc, err := ParseDocument(json)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$['']", "1"},
+		{"$.*", `1 {"": 2, "c": 3}`},
+		{"$..*", `1 {"": 2, "c": 3} 2 3`},
+		{"$..['']", "1 2"},
+		{"$.b[?@ > 2]", "3"},
+	}
+	for _, tt := range tests {
+		var got, fromDoc []string
+		for _, r := range GetMany(json, tt.path) {
+			got = append(got, r.Raw)
+		}
+		for _, r := range doc.GetMany(tt.path) {
+			fromDoc = append(fromDoc, r.Raw)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("GetMany(%q) = %v, want %s", tt.path, got, tt.want)
+		}
+		if strings.Join(fromDoc, " ") != tt.want {
+			t.Errorf("Document.GetMany(%q) = %v, want %s", tt.path, fromDoc, tt.want)
+		}
+	}
+
+	if kvs := parseValue(json).MapKVList(); len(kvs) != 2 || kvs[0].Key != "" {
+		t.Errorf("MapKVList() = %v, want the empty name first", kvs)
+	}
+}
+
+func TestResult_element(t *testing.T) {
+	json := `[{"a": "]"}, "x,\"y", [1, [2]], -1.5, null, true]`
+	arr := parseValue(json).Array()
+	r := parseValue(json)
+
+	if got := r.elementCount(); got != len(arr) {
+		t.Errorf("elementCount() = %d, want %d", got, len(arr))
+	}
+	for i, want := range arr {
+		got, ok := r.element(i)
+		if !ok || got.Raw != want.Raw || got.Index != want.Index {
+			t.Errorf("element(%d) = %q at %d, want %q at %d", i, got.Raw, got.Index, want.Raw, want.Index)
+		}
+	}
+	for _, i := range []int{-1, len(arr)} {
+		if got, ok := r.element(i); ok {
+			t.Errorf("element(%d) = %q, want none", i, got.Raw)
+		}
+	}
+	if got := parseValue(`{"a": 1}`).elementCount(); got != 0 {
+		t.Errorf("elementCount() of an object = %d, want 0", got)
+	}
+}
+
+func TestIndexAndSliceSelectors(t *testing.T) {
+	json := `[0, "1", [2], {"3": 3}, 4, 5, 6]`
+	var doc interface{}
+	if err := stdjson.Unmarshal([]byte(json), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{
+		"$[0]", "$[6]", "$[7]", "$[-1]", "$[-7]", "$[-8]",
+		"$[:]", "$[2:]", "$[:3]", "$[1:5:2]", "$[::3]", "$[10:]", "$[3:1]",
+		"$[-2:]", "$[:-2]", "$[-3:-1]", "$[-100:100:2]", "$[::-1]", "$[5:1:-2]",
+		"$[-100::-1]", "$[100:2:-1]",
+	}
+	for _, path := range paths {
+		// The Go value is selected through Index and Len only
+		want, err := QueryValues(doc, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := GetMany(json, path)
+		if len(got) != len(want) {
+			t.Errorf("GetMany(%q) returned %d results, want %d", path, len(got), len(want))
+			continue
+		}
+		for i := range got {
+			var v interface{}
+			if err := stdjson.Unmarshal([]byte(got[i].Raw), &v); err != nil || !reflect.DeepEqual(v, want[i]) {
+				t.Errorf("GetMany(%q)[%d] = %s, want %v", path, i, got[i].Raw, want[i])
+			}
+		}
+	}
+
+	// Out of range bounds are clamped as in RFC 9535 §2.3.4.2.2: to [0, len]
+	// for forward slices and to [-1, len-1] for backward ones
+	bounds := []struct {
+		path string
+		want string
+	}{
+		{"$[-100:2]", `0,"1"`},
+		{"$[10:]", ``},
+		{"$[7:100]", ``},
+		{"$[-100::-1]", ``},
+		{"$[-100:-90:-1]", ``},
+		{"$[5:-100:-2]", `5,{"3": 3},"1"`},
+		{"$[100:4:-1]", `6,5`},
+	}
+	for _, tt := range bounds {
+		var raws []string
+		for _, r := range GetMany(json, tt.path) {
+			raws = append(raws, r.Raw)
+		}
+		if got := strings.Join(raws, ","); got != tt.want {
+			t.Errorf("GetMany(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkIndexSelectorLargeArray(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`[`)
+	for i := 0; i < 100000; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"id": %d, "name": "item %d"}`, i, i)
+	}
+	sb.WriteString(`]`)
+	json := sb.String()
+	p := MustCompile("$[0].id")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.GetMany(json)
+	}
+}
+
+func BenchmarkReverseSliceLargeArray(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`[`)
+	for i := 0; i < 100000; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		fmt.Fprintf(&sb, `{"id": %d, "name": "item %d"}`, i, i)
+	}
+	sb.WriteString(`]`)
+	json := sb.String()
+	p := MustCompile("$[::-1].id")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.GetMany(json)
+	}
+}
+
+func BenchmarkNameSelectorWideObject(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`{"items": [`)
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(`{`)
+		for j := 0; j < 50; j++ {
+			fmt.Fprintf(&sb, `"field%d": "value \"%d\"", `, j, j)
+		}
+		fmt.Fprintf(&sb, `"price": %d}`, i)
+	}
+	sb.WriteString(`]}`)
+	json := sb.String()
+	p := MustCompile("$.items[?@.price > 50].field0")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.GetMany(json)
+	}
+}
@@ -0,0 +1,83 @@
+package jsonpath
+
+import (
+	"strings"
+)
+
+// layout describes how the children of an object or array are written, so
+// that inserted members and elements match the surrounding text
+type layout struct {
+	// sep separates two children, including the comma, e.g. ", " or ",\n    "
+	sep string
+	// colon separates a member name from its value, e.g. ": " or ":"
+	colon string
+}
+
+// containerLayout returns the layout of container, taken from its existing
+// children. Where a container has too few children to tell, the spacing after
+// a comma follows the spacing after the colon of the nearest member, so
+// {"a": 1} gets ", " and {"a":1} gets ",".
+func containerLayout(json string, container Node, children []childSpan) layout {
+	var l layout
+	if container.Value.IsObject() && len(children) > 0 {
+		l.colon = memberColon(json, children[len(children)-1])
+	} else {
+		l.colon = documentColon(json)
+	}
+
+	switch {
+	case len(children) >= 2:
+		n := len(children)
+		l.sep = json[children[n-2].end:children[n-1].start]
+	case len(children) == 1:
+		// reuse the whitespace after the opening bracket, so that children
+		// written on their own lines stay on their own lines
+		open := container.Value.Index
+		l.sep = "," + json[open+1:children[0].start]
+	default:
+		l.sep = ","
+	}
+	if l.sep == "," && strings.HasSuffix(l.colon, " ") {
+		l.sep = ", "
+	}
+	return l
+}
+
+// member returns the text of the member key with the raw value
+func (l layout) member(key, raw string) string {
+	return quoteString(key) + l.colon + raw
+}
+
+// memberColon returns the text between the name and the value of a member
+func memberColon(json string, c childSpan) string {
+	rawKey, _ := tostr(json[c.start:])
+	return json[c.start+len(rawKey) : c.value.Index]
+}
+
+// documentColon returns the text between the name and the value of the first
+// member in json, or ":" if there is none
+func documentColon(json string) string {
+	for i := 0; i < len(json); i++ {
+		if json[i] != '"' {
+			continue
+		}
+		raw, _ := tostr(json[i:])
+		i += len(raw)
+		j := skipWhitespaceJSON(json, i)
+		if j < len(json) && json[j] == ':' {
+			return json[i:skipWhitespaceJSON(json, j+1)]
+		}
+		i--
+	}
+	return ":"
+}
+
+// appendChildEdit returns an edit adding item as the last child of container
+func appendChildEdit(json string, container Node, children []childSpan, l layout, item string) edit {
+	if len(children) == 0 {
+		start := container.Value.Index + 1
+		return edit{start: start, end: start, text: item}
+	}
+	end := children[len(children)-1].end
+	return edit{start: end, end: end, text: l.sep + item}
+}
@@ -0,0 +1,254 @@
+package jsonpath
+
+import (
+	"testing"
+)
+
+const layoutTestConfig = `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3
+    },
+    "hosts": [
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"],
+    "empty": {}
+}
+`
+
+func TestFormattingPreservingEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(json string) (string, error)
+		want string
+	}{
+		{
+			name: "替换值",
+			edit: func(json string) (string, error) {
+				return Set(json, "$.limits.cpu", 2)
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 2,
+        "memory": 2e3
+    },
+    "hosts": [
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"],
+    "empty": {}
+}
+`,
+		},
+		{
+			name: "删除成员",
+			edit: func(json string) (string, error) {
+				s, _, err := Delete(json, "$.limits.cpu")
+				return s, err
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "memory": 2e3
+    },
+    "hosts": [
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"],
+    "empty": {}
+}
+`,
+		},
+		{
+			name: "删除末尾成员",
+			edit: func(json string) (string, error) {
+				s, _, err := Delete(json, "$.empty")
+				return s, err
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3
+    },
+    "hosts": [
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"]
+}
+`,
+		},
+		{
+			name: "删除全部元素",
+			edit: func(json string) (string, error) {
+				s, _, err := Delete(json, "$.hosts[*]")
+				return s, err
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3
+    },
+    "hosts": [],
+    "tags": ["x", "y"],
+    "empty": {}
+}
+`,
+		},
+		{
+			name: "添加成员",
+			edit: func(json string) (string, error) {
+				return AddMember(json, "$.limits", "disk", 10)
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3,
+        "disk": 10
+    },
+    "hosts": [
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"],
+    "empty": {}
+}
+`,
+		},
+		{
+			name: "添加成员到空对象",
+			edit: func(json string) (string, error) {
+				return AddMember(json, "$.empty", "k", true)
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3
+    },
+    "hosts": [
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"],
+    "empty": {"k": true}
+}
+`,
+		},
+		{
+			name: "追加元素",
+			edit: func(json string) (string, error) {
+				s, err := Append(json, "$.hosts", "c")
+				if err != nil {
+					return "", err
+				}
+				return Append(s, "$.tags", "z")
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3
+    },
+    "hosts": [
+        "a",
+        "b",
+        "c"
+    ],
+    "tags": ["x", "y", "z"],
+    "empty": {}
+}
+`,
+		},
+		{
+			name: "插入元素",
+			edit: func(json string) (string, error) {
+				return Insert(json, "$.hosts", 0, "first")
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3
+    },
+    "hosts": [
+        "first",
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"],
+    "empty": {}
+}
+`,
+		},
+		{
+			name: "创建缺失成员",
+			edit: func(json string) (string, error) {
+				return SetWithOptions(json, "$.owner.team", "infra", &SetOptions{CreateMissing: true})
+			},
+			want: `{
+    "name": "svc",
+    "limits": {
+        "cpu": 1.50,
+        "memory": 2e3
+    },
+    "hosts": [
+        "a",
+        "b"
+    ],
+    "tags": ["x", "y"],
+    "empty": {},
+    "owner": {"team": "infra"}
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.edit(layoutTestConfig)
+			if err != nil {
+				t.Fatalf("edit error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("edit =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerLayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		wantSep   string
+		wantColon string
+	}{
+		{"紧凑对象", `{"a":1,"b":2}`, ",", ":"},
+		{"带空格对象", `{"a": 1, "b": 2}`, ", ", ": "},
+		{"单成员紧凑", `{"a":1}`, ",", ":"},
+		{"单成员带空格", `{"a": 1}`, ", ", ": "},
+		{"多行对象", "{\n\t\"a\": 1\n}", ",\n\t", ": "},
+		{"空对象", `{}`, ",", ":"},
+		{"数组", `[1,  2]`, ",  ", ":"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := Node{Value: parseValue(tt.json), Location: Location{}}
+			l := containerLayout(tt.json, root, childSpans(tt.json, root.Value))
+			if l.sep != tt.wantSep || l.colon != tt.wantColon {
+				t.Errorf("containerLayout() = %q, %q, want %q, %q", l.sep, l.colon, tt.wantSep, tt.wantColon)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded is reported when a query or document exceeds one of the
+// limits set in Options
+var ErrLimitExceeded = errors.New("jsonpath: limit exceeded")
+
+// Limit identifies one of the limits of Options
+type Limit int
+
+const (
+	LimitDepth       Limit = iota + 1 // Options.MaxDepth
+	LimitNodes                        // Options.MaxNodes
+	LimitResults                      // Options.MaxResults
+	LimitQueryLength                  // Options.MaxQueryLength
+	LimitFilterDepth                  // Options.MaxFilterDepth
+	LimitRegexLength                  // Options.MaxRegexLength
+)
+
+// String returns the name of the limit
+func (l Limit) String() string {
+	switch l {
+	case LimitDepth:
+		return "nesting depth"
+	case LimitNodes:
+		return "nodes visited"
+	case LimitResults:
+		return "results"
+	case LimitQueryLength:
+		return "query length"
+	case LimitFilterDepth:
+		return "filter nesting"
+	case LimitRegexLength:
+		return "regular expression length"
+	default:
+		return "unknown"
+	}
+}
+
+// LimitError reports which limit a query or document exceeded
+type LimitError struct {
+	Limit Limit
+	// Max is the configured value of the limit
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("jsonpath: %s limit of %d exceeded", e.Limit, e.Max)
+}
+
+// Is reports whether target is ErrLimitExceeded
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// checkQueryLimits checks the limits that only depend on the query
+func checkQueryLimits(query *Query, opts *Options) error {
+	if opts.MaxFilterDepth <= 0 && opts.MaxRegexLength <= 0 {
+		return nil
+	}
+
+	var err error
+	checkRegex := func(fn *FuncCall) {
+		if opts.MaxRegexLength <= 0 || !isRegexFunction(fn.Name) || len(fn.Args) != 2 {
+			return
+		}
+		if arg := fn.Args[1]; arg.Type == FuncArgLiteral && len(arg.Literal.Value) > opts.MaxRegexLength && err == nil {
+			err = &LimitError{Limit: LimitRegexLength, Max: opts.MaxRegexLength}
+		}
+	}
+	depth := segmentsFilterDepth(query.Segments, checkRegex)
+	if err != nil {
+		return err
+	}
+	if opts.MaxFilterDepth > 0 && depth > opts.MaxFilterDepth {
+		return &LimitError{Limit: LimitFilterDepth, Max: opts.MaxFilterDepth}
+	}
+	return nil
+}
+
+// isRegexFunction reports whether the second argument of the function is a regular expression
+func isRegexFunction(name string) bool {
+	return name == "match" || name == "search"
+}
+
+// segmentsFilterDepth returns the deepest nesting of the filters of segments,
+// calling visit for every function call
+func segmentsFilterDepth(segments []*Segment, visit func(fn *FuncCall)) int {
+	depth := 0
+	for _, seg := range segments {
+		for _, sel := range seg.Selectors {
+			if sel.Type == FilterSelector {
+				depth = maxInt(depth, filterDepth(sel.Filter, visit))
+			}
+		}
+	}
+	return depth
+}
+
+// filterDepth returns the nesting depth of a filter expression. Every logical
+// operator, parenthesis, comparison, test, function call and nested filter
+// adds a level.
+func filterDepth(expr *FilterExpr, visit func(fn *FuncCall)) int {
+	if expr == nil {
+		return 0
+	}
+	switch expr.Type {
+	case FilterLogicalOr, FilterLogicalAnd:
+		return 1 + maxInt(filterDepth(expr.Left, visit), filterDepth(expr.Right, visit))
+	case FilterLogicalNot, FilterParen:
+		return 1 + filterDepth(expr.Operand, visit)
+	case FilterComparison:
+		return 1 + maxInt(comparableDepth(expr.Comp.Left, visit), comparableDepth(expr.Comp.Right, visit))
+	case FilterTest:
+		if expr.Test.FilterQuery != nil {
+			return 1 + segmentsFilterDepth(expr.Test.FilterQuery.Segments, visit)
+		}
+		return 1 + funcDepth(expr.Test.FuncExpr, visit)
+	}
+	return 1
+}
+
+func comparableDepth(c *Comparable, visit func(fn *FuncCall)) int {
+	if c.Type == ComparableFuncExpr {
+		return funcDepth(c.FuncExpr, visit)
+	}
+	return 0
+}
+
+func funcDepth(fn *FuncCall, visit func(fn *FuncCall)) int {
+	if fn == nil {
+		return 0
+	}
+	visit(fn)
+	depth := 0
+	for _, arg := range fn.Args {
+		switch arg.Type {
+		case FuncArgFilterQuery:
+			depth = maxInt(depth, segmentsFilterDepth(arg.FilterQuery.Segments, visit))
+		case FuncArgLogicalExpr:
+			depth = maxInt(depth, filterDepth(arg.LogicalExpr, visit))
+		case FuncArgFuncExpr:
+			depth = maxInt(depth, funcDepth(arg.FuncExpr, visit))
+		}
+	}
+	return 1 + depth
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,130 @@
+package jsonpath
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	deep := strings.Repeat(`{"a": `, 50) + "1" + strings.Repeat("}", 50)
+	wide := "[" + strings.Repeat("1, ", 999) + "1]"
+
+	tests := []struct {
+		name  string
+		json  string
+		path  string
+		opts  Options
+		limit Limit
+	}{
+		{"嵌套深度", deep, "$..*", Options{MaxDepth: 10}, LimitDepth},
+		{"严格模式下的嵌套深度", deep, "$.a", Options{MaxDepth: 10, Strict: true}, LimitDepth},
+		{"访问节点数", wide, "$[?@ > 1]", Options{MaxNodes: 100}, LimitNodes},
+		{"后代访问节点数", deep, "$..a", Options{MaxNodes: 10}, LimitNodes},
+		{"结果数", wide, "$[*]", Options{MaxResults: 10}, LimitResults},
+		{"文档中的正则长度", `[{"p": "aaaaaaaaaaaa"}]`, "$[?match(@.p, @.p)]", Options{MaxRegexLength: 5}, LimitRegexLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := CompileWithOptions(tt.path, &tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := p.QueryAll(tt.json)
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit || !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("QueryAll() = %d results, %v, want %s limit error", len(results), err, tt.limit)
+			}
+		})
+	}
+}
+
+func TestQueryLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		opts  Options
+		limit Limit
+	}{
+		{"查询长度", "$.a.b.c.d", Options{MaxQueryLength: 5}, LimitQueryLength},
+		{"过滤嵌套", "$[?@[?@[?@[?@ > 1]]]]", Options{MaxFilterDepth: 3}, LimitFilterDepth},
+		{"括号嵌套", "$[?((((@.a))))]", Options{MaxFilterDepth: 3}, LimitFilterDepth},
+		{"正则字面量长度", `$[?search(@.a, "abcdefgh")]`, Options{MaxRegexLength: 4}, LimitRegexLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileWithOptions(tt.path, &tt.opts)
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("CompileWithOptions() error = %v, want %s limit error", err, tt.limit)
+			}
+		})
+	}
+
+	p, err := CompileWithOptions(`$[?search(@.a, "ab")]`, &Options{MaxQueryLength: 100, MaxFilterDepth: 3, MaxRegexLength: 4})
+	if err != nil {
+		t.Fatalf("CompileWithOptions() within limits error = %v", err)
+	}
+	if results, err := p.QueryAll(`[{"a": "xab"}, {"a": "b"}]`); err != nil || len(results) != 1 {
+		t.Errorf("QueryAll() = %v, %v, want 1 result", results, err)
+	}
+
+	want := "jsonpath: nodes visited limit of 3 exceeded"
+	if got := (&LimitError{Limit: LimitNodes, Max: 3}).Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestStreamLimits(t *testing.T) {
+	deep := strings.Repeat("[", 100) + strings.Repeat("]", 100)
+	p, err := CompileWithOptions("$[0]", &Options{MaxDepth: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.QueryReader(strings.NewReader(deep), func(Node) error { return nil })
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("QueryReader() error = %v, want ErrLimitExceeded", err)
+	}
+}
+
+func TestComparisonLimits(t *testing.T) {
+	// Operands that are equal all the way down a deep nesting
+	const depth = 20000
+	doc, err := ParseDocument(strings.Repeat("[", depth) + strings.Repeat("]", depth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nested interface{} = []interface{}{}
+	for i := 1; i < depth; i++ {
+		nested = []interface{}{nested}
+	}
+
+	tests := []struct {
+		name  string
+		opts  Options
+		limit Limit
+	}{
+		{"嵌套深度", Options{MaxDepth: 8}, LimitDepth},
+		{"访问节点数", Options{MaxNodes: 100}, LimitNodes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := CompileWithOptions("$[?@ == @]", &tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, err := doc.Query(p)
+			var limitErr *LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("Query() = %d results, %v, want %s limit error", len(results), err, tt.limit)
+			}
+			values, err := p.QueryValues(nested)
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("QueryValues() = %d values, %v, want %s limit error", len(values), err, tt.limit)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package jsonpath
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineError reports a record of a JSON Lines input that could not be queried
+type LineError struct {
+	Line int // 1-based line number of the record
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("jsonpath: line %d: %s", e.Line, strings.TrimPrefix(e.Err.Error(), "jsonpath: "))
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LinesOptions configures QueryLinesWithOptions
+type LinesOptions struct {
+	// ContinueOnError skips malformed records instead of stopping at the
+	// first one. The error of the first skipped record is returned once the
+	// whole input has been read.
+	ContinueOnError bool
+}
+
+// QueryLines evaluates path against every record of newline-delimited JSON
+// (JSON Lines) read from r and calls fn with the line number and the results
+// of each record. Blank lines are skipped.
+//
+// A malformed record stops reading with a *LineError, as does an error
+// returned by fn, which is returned as is.
+func QueryLines(r io.Reader, path string, fn func(line int, results []Result) error) error {
+	return QueryLinesWithOptions(r, path, fn, nil)
+}
+
+// QueryLinesWithOptions is like QueryLines with options. A nil opts is the
+// same as the zero LinesOptions.
+func QueryLinesWithOptions(r io.Reader, path string, fn func(line int, results []Result) error, opts *LinesOptions) error {
+	p, err := compileCached(path)
+	if err != nil {
+		return err
+	}
+	return p.QueryLinesWithOptions(r, fn, opts)
+}
+
+// QueryLines evaluates the path against every record of JSON Lines read from r, see the package function QueryLines
+func (p *Path) QueryLines(r io.Reader, fn func(line int, results []Result) error) error {
+	return p.QueryLinesWithOptions(r, fn, nil)
+}
+
+// QueryLinesWithOptions is like QueryLines with options
+func (p *Path) QueryLinesWithOptions(r io.Reader, fn func(line int, results []Result) error, opts *LinesOptions) error {
+	if opts == nil {
+		opts = &LinesOptions{}
+	}
+
+	br := bufio.NewReader(r)
+	var firstErr error
+	for line := 1; ; line++ {
+		record, readErr := br.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+
+		if strings.TrimLeft(record, " \t\r\n") != "" {
+			results, err := p.queryRecord(record)
+			if jsonErr, ok := err.(*JSONError); ok {
+				jsonErr.Line = line // a record spans a single line
+			}
+			if err != nil {
+				err = &LineError{Line: line, Err: err}
+				if !opts.ContinueOnError {
+					return err
+				}
+				if firstErr == nil {
+					firstErr = err
+				}
+			} else if err := fn(line, results); err != nil {
+				return err
+			}
+		}
+
+		if readErr == io.EOF {
+			return firstErr
+		}
+	}
+}
+
+// queryRecord evaluates the path against one JSON Lines record, which is
+// validated first as by QueryAll
+func (p *Path) queryRecord(record string) ([]Result, error) {
+	if err := p.eval.validateDocument(record); err != nil {
+		return nil, err
+	}
+	return p.eval.evaluate(record)
+}
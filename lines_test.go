@@ -0,0 +1,91 @@
+package jsonpath
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryLines(t *testing.T) {
+	input := "{\"level\": \"info\", \"msg\": \"a\"}\n" +
+		"\n" +
+		"{\"level\": \"error\", \"msg\": \"b\"}\r\n" +
+		"   \n" +
+		"{\"level\": \"error\", \"msg\": \"c\"}"
+
+	var got []string
+	err := QueryLines(strings.NewReader(input), "$.msg", func(line int, results []Result) error {
+		for _, r := range results {
+			got = append(got, r.String())
+		}
+		if line == 2 || line == 4 {
+			t.Errorf("blank line %d was not skipped", line)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("QueryLines() error = %v", err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("QueryLines() = %v, want %v", got, want)
+	}
+}
+
+func TestQueryLinesErrors(t *testing.T) {
+	input := "{\"a\": 1}\n{\"a\": }\n{\"a\": 3}\n[1,\n"
+
+	var lines []int
+	fn := func(line int, results []Result) error {
+		lines = append(lines, line)
+		return nil
+	}
+
+	err := QueryLines(strings.NewReader(input), "$.a", fn)
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("QueryLines() error = %v, want *LineError for line 2", err)
+	}
+	if want := "jsonpath: line 2: invalid json at line 2, column 7 (offset 6): unexpected '}', expected value"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !reflect.DeepEqual(lines, []int{1}) {
+		t.Errorf("records before the error = %v, want [1]", lines)
+	}
+
+	lines = nil
+	err = QueryLinesWithOptions(strings.NewReader(input), "$.a", fn, &LinesOptions{ContinueOnError: true})
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 {
+		t.Errorf("QueryLinesWithOptions() error = %v, want the error of line 2", err)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 3}) {
+		t.Errorf("records read = %v, want [1 3]", lines)
+	}
+
+	errStop := errors.New("stop")
+	err = QueryLinesWithOptions(strings.NewReader(input), "$.a", func(int, []Result) error { return errStop }, &LinesOptions{ContinueOnError: true})
+	if err != errStop {
+		t.Errorf("QueryLinesWithOptions() error = %v, want the callback error", err)
+	}
+
+	if err := QueryLines(strings.NewReader(input), "$[", fn); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("QueryLines() error = %v, want ErrInvalidPath", err)
+	}
+}
+
+func TestQueryLinesMaxDepth(t *testing.T) {
+	input := "{\"a\": 1}\n{\"a\": [[[[1]]]]}\n"
+
+	for _, strict := range []bool{false, true} {
+		p, err := CompileWithOptions("$.a", &Options{MaxDepth: 3, Strict: strict})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = p.QueryLines(strings.NewReader(input), func(int, []Result) error { return nil })
+		var lineErr *LineError
+		var limitErr *LimitError
+		if !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.As(err, &limitErr) || limitErr.Limit != LimitDepth {
+			t.Errorf("QueryLines() with Strict %v error = %v, want the depth limit on line 2", strict, err)
+		}
+	}
+}
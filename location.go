@@ -0,0 +1,107 @@
+package jsonpath
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LocationStep is a single step in a Location: either an object member name
+// or an array index
+type LocationStep struct {
+	// Name is the member name, used when IsIndex is false
+	Name string
+	// Index is the array index, used when IsIndex is true
+	Index int
+	// IsIndex reports whether the step is an array index
+	IsIndex bool
+}
+
+func nameStep(name string) LocationStep {
+	return LocationStep{Name: name}
+}
+
+func indexStep(index int) LocationStep {
+	return LocationStep{Index: index, IsIndex: true}
+}
+
+// String returns the step as a normalized path segment, e.g. ['a'] or [0]
+func (s LocationStep) String() string {
+	var b strings.Builder
+	s.writeTo(&b)
+	return b.String()
+}
+
+func (s LocationStep) writeTo(b *strings.Builder) {
+	b.WriteByte('[')
+	if s.IsIndex {
+		b.WriteString(strconv.Itoa(s.Index))
+	} else {
+		b.WriteByte('\'')
+		writeNormalizedName(b, s.Name)
+		b.WriteByte('\'')
+	}
+	b.WriteByte(']')
+}
+
+// writeNormalizedName escapes a member name as required by RFC 9535 §2.7
+func writeNormalizedName(b *strings.Builder, name string) {
+	const hex = "0123456789abcdef"
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		switch ch {
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if ch < ' ' {
+				b.WriteString(`\u00`)
+				b.WriteByte(hex[ch>>4])
+				b.WriteByte(hex[ch&0xf])
+			} else {
+				b.WriteByte(ch)
+			}
+		}
+	}
+}
+
+// Location identifies a node in a document by the member names and array
+// indices leading to it from the root. An empty Location is the root itself.
+type Location []LocationStep
+
+// Child returns a new Location extended by step. The receiver is not modified.
+func (l Location) Child(step LocationStep) Location {
+	loc := make(Location, len(l)+1)
+	copy(loc, l)
+	loc[len(l)] = step
+	return loc
+}
+
+// String returns the Normalized Path of the location as defined by
+// RFC 9535 §2.7, e.g. $['store']['book'][0]['title']
+func (l Location) String() string {
+	var b strings.Builder
+	b.WriteByte('$')
+	for _, step := range l {
+		step.writeTo(&b)
+	}
+	return b.String()
+}
+
+// Node is a value selected by a query together with its location
+type Node struct {
+	// Location is the location of the value in the queried document
+	Location Location
+	// Value is the selected value
+	Value Result
+}
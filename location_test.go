@@ -0,0 +1,85 @@
+package jsonpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocation_String(t *testing.T) {
+	tests := []struct {
+		name string
+		loc  Location
+		want string
+	}{
+		{"根节点", Location{}, "$"},
+		{"对象成员", Location{nameStep("a")}, "$['a']"},
+		{"数组元素", Location{indexStep(1)}, "$[1]"},
+		{"嵌套", Location{nameStep("a"), indexStep(2), nameStep("b")}, "$['a'][2]['b']"},
+		{"单引号转义", Location{nameStep("'")}, `$['\'']`},
+		{"反斜杠转义", Location{nameStep(`\`)}, `$['\\']`},
+		{"控制字符转义", Location{nameStep("\b\f\n\r\t")}, `$['\b\f\n\r\t']`},
+		{"其他控制字符", Location{nameStep("\x0b\x1f")}, `$['\u000b\u001f']`},
+		{"双引号不转义", Location{nameStep(`"`)}, `$['"']`},
+		{"Unicode", Location{nameStep("世界")}, "$['世界']"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loc.String(); got != tt.want {
+				t.Errorf("String() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocation_Child(t *testing.T) {
+	parent := make(Location, 1, 4)
+	parent[0] = nameStep("a")
+
+	c1 := parent.Child(indexStep(0))
+	c2 := parent.Child(indexStep(1))
+	if c1.String() != "$['a'][0]" || c2.String() != "$['a'][1]" {
+		t.Errorf("Child() = %s, %s, want $['a'][0], $['a'][1]", c1, c2)
+	}
+}
+
+func TestQueryNodes(t *testing.T) {
+	json := `{"o": {"j": 1, "k": 2}, "a": [5, 3, [{"j": 4}, {"k": 6}]]}`
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{"根节点", "$", []string{"$"}},
+		{"名称选择器", "$.o.j", []string{"$['o']['j']"}},
+		{"负索引", "$.a[-1][0]", []string{"$['a'][2][0]"}},
+		{"通配符", "$.o.*", []string{"$['o']['j']", "$['o']['k']"}},
+		{"切片", "$.a[1:]", []string{"$['a'][1]", "$['a'][2]"}},
+		{"反向切片", "$.a[::-2]", []string{"$['a'][2]", "$['a'][0]"}},
+		{"过滤器", "$.a[?@ == 3]", []string{"$['a'][1]"}},
+		{"后代", "$..j", []string{"$['o']['j']", "$['a'][2][0]['j']"}},
+		{"后代通配符", "$.a..*", []string{
+			"$['a'][0]", "$['a'][1]", "$['a'][2]",
+			"$['a'][2][0]", "$['a'][2][1]",
+			"$['a'][2][0]['j']", "$['a'][2][1]['k']",
+		}},
+		{"重复选择", "$.a[0,0]", []string{"$['a'][0]", "$['a'][0]"}},
+		{"无匹配", "$.x", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := QueryNodes(json, tt.path)
+			if err != nil {
+				t.Fatalf("QueryNodes(%q) error = %v", tt.path, err)
+			}
+			var got []string
+			for _, n := range nodes {
+				got = append(got, n.Location.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryNodes(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package jsonpath
+
+// QueryMulti evaluates every path against json in a single pass and returns
+// the results of each path in the order of paths, as GetMany would.
+//
+// The paths are merged into a tree of their leading name and index
+// selectors, so a common prefix such as $.store.book is looked up once and
+// the members of an object selected by several paths are found in one scan
+// of that object. The rest of each path is evaluated from the node its prefix
+// selects. Paths compiled in strict mode validate the document on their own.
+func QueryMulti(json string, paths []*Path) [][]Result {
+	results := make([][]Result, len(paths))
+	root := parseValue(json)
+	if !root.Exists() {
+		return results
+	}
+
+	trie := &multiNode{}
+	evs := make([]*evaluation, len(paths))
+	for i, p := range paths {
+		if p.eval.opts.Strict {
+			results[i] = p.GetMany(json)
+			continue
+		}
+		evs[i] = newEvaluation(jsonValue{r: root}, &p.eval.opts)
+		trie.add(i, p.eval.query.Segments, p.eval.segments)
+	}
+	trie.walk(node{value: jsonValue{r: root}}, func(i int, n node, segments []compiledSegment) {
+		evs[i].forEachFrom(n, segments, func(n node) bool {
+			results[i] = append(results[i], valueResult(n.value))
+			return true
+		})
+	})
+	return results
+}
+
+// multiNode is a node of the tree of path prefixes merged by QueryMulti
+type multiNode struct {
+	// queries holds the paths whose prefix ends at this node
+	queries []multiQuery
+
+	names   []string
+	byName  map[string]*multiNode
+	indexes []int
+	byIndex map[int]*multiNode
+}
+
+// multiQuery is the rest of a path to evaluate from a multiNode
+type multiQuery struct {
+	path     int
+	segments []compiledSegment
+}
+
+// add merges the leading name and index selectors of segments into the
+// tree. compiled holds the compiled form of segments.
+func (m *multiNode) add(path int, segments []*Segment, compiled []compiledSegment) {
+	if len(segments) == 0 || segments[0].Type != ChildSegment || len(segments[0].Selectors) != 1 {
+		m.queries = append(m.queries, multiQuery{path: path, segments: compiled})
+		return
+	}
+
+	sel := segments[0].Selectors[0]
+	switch sel.Type {
+	case NameSelector:
+		child, ok := m.byName[sel.Name]
+		if !ok {
+			if m.byName == nil {
+				m.byName = make(map[string]*multiNode)
+			}
+			child = &multiNode{}
+			m.byName[sel.Name] = child
+			m.names = append(m.names, sel.Name)
+		}
+		child.add(path, segments[1:], compiled[1:])
+	case IndexSelector:
+		child, ok := m.byIndex[sel.Index]
+		if !ok {
+			if m.byIndex == nil {
+				m.byIndex = make(map[int]*multiNode)
+			}
+			child = &multiNode{}
+			m.byIndex[sel.Index] = child
+			m.indexes = append(m.indexes, sel.Index)
+		}
+		child.add(path, segments[1:], compiled[1:])
+	default:
+		m.queries = append(m.queries, multiQuery{path: path, segments: compiled})
+	}
+}
+
+// walk calls eval for every path with the node its prefix selects and the
+// segments left to evaluate from it
+func (m *multiNode) walk(n node, eval func(path int, n node, segments []compiledSegment)) {
+	for _, q := range m.queries {
+		eval(q.path, n, q.segments)
+	}
+
+	child := func(v Value) node {
+		return node{value: v, depth: n.depth + 1}
+	}
+
+	switch {
+	case len(m.names) == 1:
+		if v, ok := n.value.Member(m.names[0]); ok {
+			m.byName[m.names[0]].walk(child(v), eval)
+		}
+	case len(m.names) > 1 && n.value.Kind() == KindObject:
+		// Find every name in one scan, the last member of a name wins
+		found := make(map[string]Value, len(m.names))
+		n.value.Each(func(step LocationStep, v Value) bool {
+			if _, ok := m.byName[step.Name]; ok {
+				found[step.Name] = v
+			}
+			return true
+		})
+		for _, name := range m.names {
+			if v, ok := found[name]; ok {
+				m.byName[name].walk(child(v), eval)
+			}
+		}
+	}
+
+	if len(m.indexes) > 0 && n.value.Kind() == KindArray {
+		length := -1
+		for _, i := range m.indexes {
+			index := i
+			if index < 0 {
+				if length < 0 {
+					length = n.value.Len()
+				}
+				index += length
+			}
+			if v, ok := n.value.Index(index); ok {
+				m.byIndex[i].walk(child(v), eval)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryMulti(t *testing.T) {
+	json := `{
+		"store": {
+			"book": [
+				{"title": "A", "price": 8.95},
+				{"title": "B", "price": 12.99},
+				{"title": "C", "price": 8.99, "isbn": "0-553"}
+			],
+			"bicycle": {"color": "red", "price": 399}
+		},
+		"max": 10,
+		"dup": 1, "dup": 2
+	}`
+
+	exprs := []string{
+		"$",
+		"$.store.book[0].title",
+		"$.store.book[-1].title",
+		"$.store.book[2].title",
+		"$.store.book[5]",
+		"$.store.book[*].title",
+		"$.store.book[?@.price < $.max].title",
+		"$.store.bicycle.color",
+		"$.store.bicycle",
+		"$..price",
+		"$['store','max']",
+		"$.dup",
+		"$.max.nothing",
+		"$.nothing",
+	}
+	var paths []*Path
+	for _, expr := range exprs {
+		paths = append(paths, MustCompile(expr))
+	}
+
+	got := QueryMulti(json, paths)
+	if len(got) != len(paths) {
+		t.Fatalf("QueryMulti() returned %d result sets, want %d", len(got), len(paths))
+	}
+	for i, p := range paths {
+		if want := p.GetMany(json); !reflect.DeepEqual(got[i], want) {
+			t.Errorf("QueryMulti() results of %s = %v, want %v", p, got[i], want)
+		}
+	}
+}
+
+func TestQueryMulti_EmptyNames(t *testing.T) {
+	json := `{"b": {"": 2, "c": [1, {"": 3}]}}`
+	paths := []*Path{MustCompile("$.b.c[1]"), MustCompile("$.b['']"), MustCompile("$.b.c[1]['']")}
+
+	got := QueryMulti(json, paths)
+	for i, p := range paths {
+		want := p.GetMany(json)
+		if len(want) == 0 {
+			t.Fatalf("GetMany(%s) returned no results", p)
+		}
+		if !reflect.DeepEqual(got[i], want) {
+			t.Errorf("QueryMulti() results of %s = %v, want %v", p, got[i], want)
+		}
+	}
+}
+
+func TestQueryMulti_Errors(t *testing.T) {
+	paths := []*Path{MustCompile("$.a"), MustCompile("$[0]")}
+	got := QueryMulti(`{"a": `, paths)
+	if len(got) != 2 || got[0] != nil || got[1] != nil {
+		t.Errorf("QueryMulti() of malformed JSON = %v, want no results", got)
+	}
+
+	strict, err := CompileWithOptions("$.a", &Options{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = QueryMulti(`{"a": 1, }`, []*Path{strict, MustCompile("$.a")})
+	if got[0] != nil || len(got[1]) != 1 {
+		t.Errorf("QueryMulti() = %v, want no results for the strict path only", got)
+	}
+}
+
+func BenchmarkQueryMulti(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`{"event": {`)
+	for i := 0; i < 80; i++ {
+		fmt.Fprintf(&sb, `"field%d": {"value": "%s"}, `, i, strings.Repeat("x", 20))
+	}
+	sb.WriteString(`"end": true}}`)
+	json := sb.String()
+
+	var paths []*Path
+	for i := 0; i < 80; i++ {
+		paths = append(paths, MustCompile(fmt.Sprintf("$.event.field%d.value", i)))
+	}
+
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, p := range paths {
+				p.GetMany(json)
+			}
+		}
+	})
+	b.Run("QueryMulti", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			QueryMulti(json, paths)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package jsonpath
+
+// Options configures how a compiled Path evaluates documents
+type Options struct {
+	// Strict validates every document with Validate before evaluating it and
+	// reports an error for invalid documents instead of returning partial
+	// results. It also rejects the NaN and Infinity spellings the lenient
+	// parser accepts.
+	Strict bool
+
+	// Limits for untrusted queries and documents. Zero means no limit.
+	// Exceeding one stops the evaluation with a *LimitError.
+
+	// MaxDepth limits how deep below the root the evaluation descends, and
+	// how deep the comparison of two arrays or objects goes
+	MaxDepth int
+	// MaxNodes limits the number of nodes visited, including filter
+	// candidates and the elements and members compared
+	MaxNodes int
+	// MaxResults limits the number of nodes selected by the query
+	MaxResults int
+	// MaxQueryLength limits the length of the path in bytes
+	MaxQueryLength int
+	// MaxFilterDepth limits the nesting of filter expressions. Every logical
+	// operator, parenthesis, comparison, function call and nested filter adds
+	// a level.
+	MaxFilterDepth int
+	// MaxRegexLength limits the length of the patterns of match() and search()
+	MaxRegexLength int
+}
+
+// CompileWithOptions is like Compile with options. A nil opts is the same as
+// the zero Options. Limits that only depend on the query are checked here.
+func CompileWithOptions(path string, opts *Options) (*Path, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.MaxQueryLength > 0 && len(path) > opts.MaxQueryLength {
+		return nil, &LimitError{Limit: LimitQueryLength, Max: opts.MaxQueryLength}
+	}
+	p, err := Compile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkQueryLimits(p.eval.query, opts); err != nil {
+		return nil, err
+	}
+	p.eval.opts = *opts
+	return p, nil
+}
@@ -4,7 +4,8 @@ import (
 	"fmt"
 )
 
-// Parse parses a JSONPath expression string and returns an AST
+// Parse parses a JSONPath expression string and returns an AST.
+// Errors are reported as *PathError.
 func Parse(path string) (*Query, error) {
 	lexer := NewLexer(path)
 	p := &Parser{
@@ -12,7 +13,11 @@ func Parse(path string) (*Query, error) {
 	}
 	p.advance()
 	p.advance()
-	return p.parseQuery()
+	query, err := p.parseQuery()
+	if err != nil {
+		return nil, &PathError{Path: path, Err: err}
+	}
+	return query, nil
 }
 
 // Parser parses JSONPath expressions into an AST
@@ -0,0 +1,133 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+)
+
+// Path is a compiled JSONPath query.
+//
+// A Path holds only the parsed query and is safe for concurrent use by
+// multiple goroutines, so it can be compiled once and evaluated against any
+// number of documents.
+type Path struct {
+	path string
+	eval *Evaluator
+}
+
+// Compile parses a JSONPath expression and returns a reusable Path
+func Compile(path string) (*Path, error) {
+	query, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{
+		path: path,
+		eval: NewEvaluator(query),
+	}, nil
+}
+
+// MustCompile is like Compile but panics if the expression cannot be parsed
+func MustCompile(path string) *Path {
+	p, err := Compile(path)
+	if err != nil {
+		panic(fmt.Sprintf("jsonpath: Compile(%q): %v", path, err))
+	}
+	return p
+}
+
+// String returns the source text of the compiled expression
+func (p *Path) String() string {
+	return p.path
+}
+
+// Get evaluates the path against json and returns the first result. The
+// evaluation stops at the first match.
+func (p *Path) Get(json string) Result {
+	var first Result
+	p.eval.forEach(json, func(r Result) bool {
+		first = r
+		return false
+	})
+	return first
+}
+
+// GetBytes evaluates the path against json with []byte input
+func (p *Path) GetBytes(json []byte) Result {
+	return p.Get(string(json))
+}
+
+// GetMany evaluates the path against json and returns all results
+func (p *Path) GetMany(json string) []Result {
+	return p.eval.Evaluate(json)
+}
+
+// GetManyBytes evaluates the path against json with []byte input
+func (p *Path) GetManyBytes(json []byte) []Result {
+	return p.GetMany(string(json))
+}
+
+// QueryAll evaluates the path against json and returns all results.
+//
+// Unlike GetMany, QueryAll validates json first and reports malformed JSON
+// (ErrInvalidJSON) and function extensions that cannot be evaluated
+// (ErrFunction) as errors.
+func (p *Path) QueryAll(json string) ([]Result, error) {
+	if err := p.eval.validateDocument(json); err != nil {
+		return nil, err
+	}
+	results, err := p.eval.evaluate(json)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueryContext is like QueryAll but stops evaluating once ctx is done and
+// returns ctx.Err(). Cancellation is checked between segments and while
+// walking descendants and filter candidates.
+func (p *Path) QueryContext(ctx context.Context, json string) ([]Result, error) {
+	if err := p.eval.validateDocument(json); err != nil {
+		return nil, err
+	}
+	results, err := p.eval.evaluateContext(ctx, json)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// QueryOne evaluates the path against json and returns the first result.
+// A query without matches returns an empty Result and a nil error.
+func (p *Path) QueryOne(json string) (Result, error) {
+	results, err := p.QueryAll(json)
+	if err != nil || len(results) == 0 {
+		return Result{}, err
+	}
+	return results[0], nil
+}
+
+// ForEachMatch evaluates the path against json and calls fn for every result
+// as soon as it is found. The evaluation stops when fn returns false, so
+// finding the first few matches in a large array does not evaluate the rest.
+// Errors are reported as by QueryAll, and json is validated before fn is
+// first called.
+func (p *Path) ForEachMatch(json string, fn func(r Result) bool) error {
+	if err := p.eval.validateDocument(json); err != nil {
+		return err
+	}
+	return p.eval.forEach(json, fn)
+}
+
+// QueryNodes evaluates the path against json and returns all selected nodes
+// together with their locations. Errors are reported as by QueryAll.
+func (p *Path) QueryNodes(json string) ([]Node, error) {
+	if err := p.eval.validateDocument(json); err != nil {
+		return nil, err
+	}
+	nodes, err := p.eval.evaluateNodes(json)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
@@ -0,0 +1,68 @@
+package jsonpath
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"根标识符", "$", false},
+		{"名称选择器", "$.store.book", false},
+		{"过滤器", "$..book[?@.price < 10]", false},
+		{"缺少根标识符", "store.book", true},
+		{"未闭合括号", "$.store[", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Compile(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Compile(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+			if err == nil && p.String() != tt.path {
+				t.Errorf("String() = %q, want %q", p.String(), tt.path)
+			}
+		})
+	}
+}
+
+func TestMustCompilePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustCompile did not panic on invalid path")
+		}
+	}()
+	MustCompile("$[")
+}
+
+func TestPath_Concurrent(t *testing.T) {
+	p := MustCompile("$.items[?@.n > 1].n")
+	docs := []string{
+		`{"items": [{"n": 1}, {"n": 2}, {"n": 3}]}`,
+		`{"items": [{"n": 5}]}`,
+		`{"items": []}`,
+	}
+	want := []int{2, 1, 0}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				for j, doc := range docs {
+					if got := len(p.GetMany(doc)); got != want[j] {
+						t.Errorf("GetMany(%q) len = %d, want %d", doc, got, want[j])
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,600 @@
+package jsonpath
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotStreamable is reported by QueryReader for queries that need more than
+// one element of the document in memory at a time
+var ErrNotStreamable = errors.New("jsonpath: query cannot be streamed")
+
+// QueryReader evaluates path against the JSON document read from r and calls
+// fn for every selected node as soon as it has been read. The document is not
+// buffered: only the selected nodes, and the candidates of a filter selector,
+// are held in memory one at a time.
+//
+// Streamable queries use name, wildcard and non-negative index selectors,
+// slices with non-negative bounds and a positive step, and filters that only
+// refer to the current node (@). Other queries fail with ErrNotStreamable
+// before anything is read.
+//
+// Nodes are reported in document order, which differs from the order of Get
+// when a segment has several selectors or a descendant segment selects nested
+// nodes. The Index of every result is its byte offset in the stream. Reading
+// stops at the first error, or when fn returns an error, which is returned.
+func QueryReader(r io.Reader, path string, fn func(n Node) error) error {
+	p, err := compileCached(path)
+	if err != nil {
+		return err
+	}
+	return p.QueryReader(r, fn)
+}
+
+// QueryReader evaluates the path against the JSON document read from r, see
+// the package function QueryReader
+func (p *Path) QueryReader(r io.Reader, fn func(n Node) error) error {
+	if err := checkStreamable(p.eval.query); err != nil {
+		return err
+	}
+
+	opts := &p.eval.opts
+	s := &streamScanner{r: bufio.NewReader(r), line: 1, col: 1, maxDepth: opts.MaxDepth}
+	w := &streamWalker{s: s, segments: p.eval.query.Segments, compiled: p.eval.segments, fn: fn, opts: opts}
+	if err := s.skipSpace(); err != nil {
+		return err
+	}
+	if err := w.walk(Location{}, []streamState{{}}); err != nil {
+		return err
+	}
+	if err := s.skipSpace(); err != nil {
+		return err
+	}
+	if c, err := s.peek(); err == nil {
+		return s.errorf("unexpected %q after top-level value", c)
+	}
+	return nil
+}
+
+// checkStreamable reports why query cannot be evaluated by QueryReader
+func checkStreamable(query *Query) error {
+	for _, seg := range query.Segments {
+		for _, sel := range seg.Selectors {
+			switch sel.Type {
+			case IndexSelector:
+				if sel.Index < 0 {
+					return fmt.Errorf("%w: negative index %d needs the array length", ErrNotStreamable, sel.Index)
+				}
+			case SliceSelector:
+				s := sel.Slice
+				if s.Step != nil && *s.Step < 0 || s.Start != nil && *s.Start < 0 || s.End != nil && *s.End < 0 {
+					return fmt.Errorf("%w: slices need non-negative bounds and a positive step", ErrNotStreamable)
+				}
+			case FilterSelector:
+				if filterUsesRoot(sel.Filter) {
+					return fmt.Errorf("%w: filter refers to the root node ($)", ErrNotStreamable)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// filterUsesRoot reports whether expr contains an absolute query
+func filterUsesRoot(expr *FilterExpr) bool {
+	if expr == nil {
+		return false
+	}
+	switch expr.Type {
+	case FilterLogicalOr, FilterLogicalAnd:
+		return filterUsesRoot(expr.Left) || filterUsesRoot(expr.Right)
+	case FilterLogicalNot, FilterParen:
+		return filterUsesRoot(expr.Operand)
+	case FilterComparison:
+		return comparableUsesRoot(expr.Comp.Left) || comparableUsesRoot(expr.Comp.Right)
+	case FilterTest:
+		if expr.Test.FilterQuery != nil {
+			return filterQueryUsesRoot(expr.Test.FilterQuery)
+		}
+		return funcUsesRoot(expr.Test.FuncExpr)
+	}
+	return false
+}
+
+func comparableUsesRoot(c *Comparable) bool {
+	switch c.Type {
+	case ComparableSingularQuery:
+		return !c.SingularQuery.Relative
+	case ComparableFuncExpr:
+		return funcUsesRoot(c.FuncExpr)
+	}
+	return false
+}
+
+func filterQueryUsesRoot(fq *FilterQuery) bool {
+	if !fq.Relative {
+		return true
+	}
+	for _, seg := range fq.Segments {
+		for _, sel := range seg.Selectors {
+			if sel.Type == FilterSelector && filterUsesRoot(sel.Filter) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func funcUsesRoot(fn *FuncCall) bool {
+	if fn == nil {
+		return false
+	}
+	for _, arg := range fn.Args {
+		switch arg.Type {
+		case FuncArgFilterQuery:
+			if filterQueryUsesRoot(arg.FilterQuery) {
+				return true
+			}
+		case FuncArgLogicalExpr:
+			if filterUsesRoot(arg.LogicalExpr) {
+				return true
+			}
+		case FuncArgFuncExpr:
+			if funcUsesRoot(arg.FuncExpr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// streamState is a partial match of the query: segments[:k] selected the
+// value. With a filter, the value is a candidate of the filter of segment k
+// and moves on to k+1 if it passes.
+type streamState struct {
+	k      int
+	filter filterFunc
+}
+
+// streamWalker matches the query against the values read by s
+type streamWalker struct {
+	s        *streamScanner
+	segments []*Segment
+	compiled []compiledSegment
+	fn       func(n Node) error
+	opts     *Options
+	results  int
+}
+
+// walk reads the next value, which is reached through loc with the given states
+func (w *streamWalker) walk(loc Location, states []streamState) error {
+	if len(states) == 0 {
+		return w.s.skipValue()
+	}
+	for _, st := range states {
+		if st.filter != nil || st.k == len(w.segments) {
+			return w.buffered(loc, states)
+		}
+	}
+
+	c, err := w.s.peek()
+	if err != nil {
+		return w.s.unexpected("value")
+	}
+	switch c {
+	case '{':
+		return w.s.object(func(key string) error {
+			step := nameStep(key)
+			return w.walk(loc.Child(step), w.childStates(states, step))
+		})
+	case '[':
+		return w.s.array(func(i int) error {
+			step := indexStep(i)
+			return w.walk(loc.Child(step), w.childStates(states, step))
+		})
+	}
+	// scalars have no children to select
+	return w.s.skipValue()
+}
+
+// childStates returns the states of the child reached from a value in states by step
+func (w *streamWalker) childStates(states []streamState, step LocationStep) []streamState {
+	var next []streamState
+	for _, st := range states {
+		seg := w.segments[st.k]
+		for j, sel := range seg.Selectors {
+			switch sel.Type {
+			case NameSelector:
+				if !step.IsIndex && step.Name == sel.Name {
+					next = append(next, streamState{k: st.k + 1})
+				}
+			case WildcardSelector:
+				next = append(next, streamState{k: st.k + 1})
+			case IndexSelector:
+				if step.IsIndex && step.Index == sel.Index {
+					next = append(next, streamState{k: st.k + 1})
+				}
+			case SliceSelector:
+				if step.IsIndex && inStreamSlice(sel.Slice, step.Index) {
+					next = append(next, streamState{k: st.k + 1})
+				}
+			case FilterSelector:
+				next = append(next, streamState{k: st.k, filter: w.compiled[st.k].filters[j]})
+			}
+		}
+		if seg.Type == DescendantSegment {
+			next = append(next, st)
+		}
+	}
+	return next
+}
+
+// inStreamSlice reports whether the slice selects index i of an array of unknown length
+func inStreamSlice(slice *SliceParams, i int) bool {
+	start, step := 0, 1
+	if slice.Start != nil {
+		start = *slice.Start
+	}
+	if slice.Step != nil {
+		step = *slice.Step
+	}
+	if step <= 0 || i < start || slice.End != nil && i >= *slice.End {
+		return false
+	}
+	return (i-start)%step == 0
+}
+
+// buffered reads the next value into memory and finishes every state on it
+func (w *streamWalker) buffered(loc Location, states []streamState) error {
+	start := w.s.offset
+	raw, err := w.s.captureValue()
+	if err != nil {
+		return err
+	}
+	v := jsonValue{r: parseValue(raw)}
+
+	for _, st := range states {
+		k := st.k
+		if st.filter != nil {
+			ev := newEvaluation(v, w.opts)
+			pass := st.filter(ev, v)
+			if ev.err != nil {
+				return ev.err
+			}
+			if !pass {
+				continue
+			}
+			k++
+		}
+		if err := w.finish(loc, v, k, start); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish evaluates the segments from k on v in memory and reports the results
+func (w *streamWalker) finish(loc Location, v Value, k, offset int) error {
+	ev := newEvaluation(v, w.opts)
+	ev.trackLocations = true
+	nodes := ev.evaluate(w.compiled[k:])
+	if ev.err != nil {
+		return ev.err
+	}
+	for _, n := range nodes {
+		w.results++
+		if max := w.opts.MaxResults; max > 0 && w.results > max {
+			return &LimitError{Limit: LimitResults, Max: max}
+		}
+		r := valueResult(n.value)
+		r.Index += offset
+		nodeLoc := loc
+		if len(n.loc) > 0 {
+			nodeLoc = append(append(Location{}, loc...), n.loc...)
+		}
+		if err := w.fn(Node{Location: nodeLoc, Value: r}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamScanner reads JSON tokens from a reader, tracking the position for errors
+type streamScanner struct {
+	r         *bufio.Reader
+	offset    int
+	line, col int
+
+	depth, maxDepth int
+
+	// capture collects the bytes read while it is not nil
+	capture []byte
+}
+
+func (s *streamScanner) errorf(format string, args ...interface{}) error {
+	return &JSONError{Offset: s.offset, Line: s.line, Column: s.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// unexpected reports the next byte, or the end of input
+func (s *streamScanner) unexpected(expected string) error {
+	c, err := s.peek()
+	if err == io.EOF {
+		return s.errorf("unexpected end of input, expected %s", expected)
+	}
+	if err != nil {
+		return err
+	}
+	return s.errorf("unexpected %q, expected %s", c, expected)
+}
+
+func (s *streamScanner) peek() (byte, error) {
+	b, err := s.r.Peek(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (s *streamScanner) next() (byte, error) {
+	c, err := s.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	s.offset++
+	if c == '\n' {
+		s.line++
+		s.col = 1
+	} else if c&0xC0 != 0x80 {
+		s.col++
+	}
+	if s.capture != nil {
+		s.capture = append(s.capture, c)
+	}
+	return c, nil
+}
+
+// expect consumes c or reports what was found instead
+func (s *streamScanner) expect(c byte, expected string) error {
+	if got, err := s.peek(); err != nil || got != c {
+		return s.unexpected(expected)
+	}
+	_, err := s.next()
+	return err
+}
+
+func (s *streamScanner) skipSpace() error {
+	for {
+		c, err := s.peek()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return nil
+		}
+		s.next()
+	}
+}
+
+// captureValue reads the next value and returns its text
+func (s *streamScanner) captureValue() (string, error) {
+	s.capture = []byte{}
+	err := s.skipValue()
+	raw := string(s.capture)
+	s.capture = nil
+	return raw, err
+}
+
+// skipValue reads the next value, checking that it is well formed
+func (s *streamScanner) skipValue() error {
+	c, err := s.peek()
+	if err != nil {
+		return s.unexpected("value")
+	}
+	switch {
+	case c == '{':
+		return s.object(func(string) error { return s.skipValue() })
+	case c == '[':
+		return s.array(func(int) error { return s.skipValue() })
+	case c == '"':
+		return s.string()
+	case c == 't':
+		return s.literal("true")
+	case c == 'f':
+		return s.literal("false")
+	case c == 'n':
+		return s.literal("null")
+	case c == '-' || c >= '0' && c <= '9':
+		return s.number()
+	}
+	return s.unexpected("value")
+}
+
+// object reads an object, calling member to read the value of every member
+func (s *streamScanner) object(member func(key string) error) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	s.next() // '{'
+	if err := s.skipSpace(); err != nil {
+		return err
+	}
+	if c, err := s.peek(); err == nil && c == '}' {
+		s.next()
+		return nil
+	}
+	for {
+		key, err := s.key()
+		if err != nil {
+			return err
+		}
+		if err := s.skipSpace(); err != nil {
+			return err
+		}
+		if err := s.expect(':', "':'"); err != nil {
+			return err
+		}
+		if err := s.skipSpace(); err != nil {
+			return err
+		}
+		if err := member(key); err != nil {
+			return err
+		}
+		if err := s.skipSpace(); err != nil {
+			return err
+		}
+		c, err := s.peek()
+		if err != nil || c != ',' && c != '}' {
+			return s.unexpected("',' or '}'")
+		}
+		s.next()
+		if c == '}' {
+			return nil
+		}
+		if err := s.skipSpace(); err != nil {
+			return err
+		}
+	}
+}
+
+// enter records entering an object or array, checking the depth limit
+func (s *streamScanner) enter() error {
+	s.depth++
+	if s.maxDepth > 0 && s.depth > s.maxDepth {
+		return &LimitError{Limit: LimitDepth, Max: s.maxDepth}
+	}
+	return nil
+}
+
+func (s *streamScanner) leave() {
+	s.depth--
+}
+
+// key reads and decodes an object member name
+func (s *streamScanner) key() (string, error) {
+	if c, err := s.peek(); err != nil || c != '"' {
+		return "", s.unexpected("string")
+	}
+	outer := s.capture
+	s.capture = []byte{}
+	err := s.string()
+	raw := string(s.capture)
+	if outer != nil {
+		s.capture = append(outer, s.capture...)
+	} else {
+		s.capture = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	_, key := tostr(raw)
+	return key, nil
+}
+
+// array reads an array, calling elem to read every element
+func (s *streamScanner) array(elem func(i int) error) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	s.next() // '['
+	if err := s.skipSpace(); err != nil {
+		return err
+	}
+	if c, err := s.peek(); err == nil && c == ']' {
+		s.next()
+		return nil
+	}
+	for i := 0; ; i++ {
+		if err := elem(i); err != nil {
+			return err
+		}
+		if err := s.skipSpace(); err != nil {
+			return err
+		}
+		c, err := s.peek()
+		if err != nil || c != ',' && c != ']' {
+			return s.unexpected("',' or ']'")
+		}
+		s.next()
+		if c == ']' {
+			return nil
+		}
+		if err := s.skipSpace(); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *streamScanner) string() error {
+	s.next() // '"'
+	for {
+		c, err := s.peek()
+		if err != nil {
+			return s.unexpected("'\"'")
+		}
+		switch {
+		case c == '"':
+			s.next()
+			return nil
+		case c < 0x20:
+			return s.errorf("invalid control character %q in string", c)
+		case c == '\\':
+			s.next()
+			e, err := s.peek()
+			if err != nil {
+				return s.unexpected("escape sequence")
+			}
+			switch e {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				s.next()
+			case 'u':
+				s.next()
+				for j := 0; j < 4; j++ {
+					h, err := s.peek()
+					if err != nil || !isHexDigit(h) {
+						return s.unexpected("hex digit")
+					}
+					s.next()
+				}
+			default:
+				return s.errorf("invalid escape character %q", e)
+			}
+		default:
+			s.next()
+		}
+	}
+}
+
+func (s *streamScanner) number() error {
+	start := s.offset
+	line, col := s.line, s.col
+	var num []byte
+	for {
+		c, err := s.peek()
+		if err != nil || !(c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E') {
+			break
+		}
+		s.next()
+		num = append(num, c)
+	}
+	if err := Validate(string(num)); err != nil {
+		return &JSONError{Offset: start, Line: line, Column: col, Msg: fmt.Sprintf("invalid number %q", num)}
+	}
+	return nil
+}
+
+func (s *streamScanner) literal(lit string) error {
+	for i := 0; i < len(lit); i++ {
+		if c, err := s.peek(); err != nil || c != lit[i] {
+			return s.unexpected(fmt.Sprintf("%q", lit))
+		}
+		s.next()
+	}
+	return nil
+}
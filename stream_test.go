@@ -0,0 +1,189 @@
+package jsonpath
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestQueryReader(t *testing.T) {
+	json := `{
+		"store": {
+			"book": [
+				{"title": "A", "price": 8.95, "tags": ["x"]},
+				{"title": "B!", "price": 12.99},
+				{"title": "C", "price": 8.99, "isbn": "0-553"}
+			],
+			"bicycle": {"color": "red", "price": 399}
+		}
+	}`
+
+	tests := []struct {
+		name string
+		path string
+		// documentOrder is set when Get visits the nodes in a different order
+		documentOrder bool
+	}{
+		{"根节点", "$", false},
+		{"成员", "$.store.bicycle.color", false},
+		{"通配符", "$.store.book[*].title", false},
+		{"索引", "$.store.book[1]", false},
+		{"切片", "$.store.book[1:].title", false},
+		{"带步长的切片", "$.store.book[::2].title", false},
+		{"后代", "$..price", false},
+		{"后代通配符", "$.store.book..*", true},
+		{"过滤", "$.store.book[?@.price < 10].title", false},
+		{"过滤函数", "$.store.book[?length(@.tags) == 1].title", false},
+		{"过滤存在性", "$..book[?@.isbn]", false},
+		{"无匹配", "$.nothing", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := QueryReader(strings.NewReader(json), tt.path, func(n Node) error {
+				if json[n.Value.Index:n.Value.Index+len(n.Value.Raw)] != n.Value.Raw {
+					t.Errorf("Index %d of %s does not point to its text", n.Value.Index, n.Location)
+				}
+				got = append(got, n.Location.String()+"="+n.Value.Raw)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("QueryReader() error = %v", err)
+			}
+
+			nodes, _ := QueryNodes(json, tt.path)
+			var want []string
+			for _, n := range nodes {
+				want = append(want, n.Location.String()+"="+n.Value.Raw)
+			}
+			if tt.documentOrder {
+				sort.Strings(got)
+				sort.Strings(want)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("QueryReader() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// failingReader returns err once its data is exhausted
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestQueryReader_Incremental(t *testing.T) {
+	errBroken := errors.New("connection lost")
+	r := &failingReader{data: `{"items": [{"id": 1}, {"id": 2}, {"id"`, err: errBroken}
+
+	var ids []int64
+	err := QueryReader(r, "$.items[*].id", func(n Node) error {
+		ids = append(ids, n.Value.Int())
+		return nil
+	})
+	if !errors.Is(err, errBroken) {
+		t.Errorf("QueryReader() error = %v, want %v", err, errBroken)
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Errorf("results before the error = %v, want [1 2]", ids)
+	}
+}
+
+func TestQueryReader_Stop(t *testing.T) {
+	errStop := errors.New("stop")
+	count := 0
+	err := QueryReader(strings.NewReader(`[1, 2, 3, 4]`), "$[*]", func(n Node) error {
+		count++
+		if count == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop || count != 2 {
+		t.Errorf("QueryReader() = %v after %d results, want %v after 2", err, count, errStop)
+	}
+}
+
+func TestQueryReader_Errors(t *testing.T) {
+	notStreamable := []string{
+		"$[-1]",
+		"$[::-1]",
+		"$[-2:]",
+		"$[?@.price < $.max]",
+		"$[?count($..a) > 1]",
+		"$[?@[?@ == $.a]]",
+	}
+	for _, path := range notStreamable {
+		err := QueryReader(strings.NewReader(`[]`), path, func(Node) error { return nil })
+		if !errors.Is(err, ErrNotStreamable) {
+			t.Errorf("QueryReader(%q) error = %v, want ErrNotStreamable", path, err)
+		}
+	}
+
+	if err := QueryReader(strings.NewReader(`[]`), "$[", func(Node) error { return nil }); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("QueryReader() error = %v, want ErrInvalidPath", err)
+	}
+
+	tests := []struct {
+		json   string
+		line   int
+		column int
+		msg    string
+	}{
+		{"{\n  \"a\": [1, x]\n}", 2, 12, "unexpected 'x', expected value"},
+		{`{"a": 1`, 1, 8, "unexpected end of input, expected ',' or '}'"},
+		{`[1] 2`, 1, 5, "unexpected '2' after top-level value"},
+		{`{"a": 01}`, 1, 7, `invalid number "01"`},
+		{`["a` + "\x01" + `"]`, 1, 4, `invalid control character '\x01' in string`},
+	}
+	for _, tt := range tests {
+		err := QueryReader(strings.NewReader(tt.json), "$..a", func(Node) error { return nil })
+		var jsonErr *JSONError
+		if !errors.As(err, &jsonErr) {
+			t.Errorf("QueryReader(%q) error = %v, want *JSONError", tt.json, err)
+			continue
+		}
+		if jsonErr.Line != tt.line || jsonErr.Column != tt.column || jsonErr.Msg != tt.msg {
+			t.Errorf("QueryReader(%q) error at %d:%d %q, want %d:%d %q", tt.json,
+				jsonErr.Line, jsonErr.Column, jsonErr.Msg, tt.line, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestQueryReader_LargeDocument(t *testing.T) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte(`{"rows": [`))
+		for i := 0; i < 10000; i++ {
+			if i > 0 {
+				pw.Write([]byte(","))
+			}
+			pw.Write([]byte(`{"n": 1, "pad": "` + strings.Repeat("x", 100) + `"}`))
+		}
+		pw.Write([]byte(`]}`))
+		pw.Close()
+	}()
+
+	sum := int64(0)
+	err := QueryReader(pr, "$.rows[?@.n == 1].n", func(n Node) error {
+		sum += n.Value.Int()
+		return nil
+	})
+	if err != nil || sum != 10000 {
+		t.Errorf("QueryReader() = %d, %v, want 10000", sum, err)
+	}
+}
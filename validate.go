@@ -0,0 +1,271 @@
+package jsonpath
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Valid reports whether json is a valid JSON document as defined by RFC 8259
+func Valid(json string) bool {
+	return Validate(json) == nil
+}
+
+// ValidBytes reports whether json is a valid JSON document
+func ValidBytes(json []byte) bool {
+	return Valid(string(json))
+}
+
+// Validate checks that json is a valid JSON document as defined by RFC 8259.
+// The error is a *JSONError reporting where the problem is and what was
+// expected. Unlike the lenient parser, NaN, Infinity and other non-standard
+// number spellings are rejected.
+func Validate(json string) error {
+	return validate(json, 0)
+}
+
+// validate is like Validate and also rejects documents nested deeper than
+// maxDepth when it is positive
+func validate(json string, maxDepth int) error {
+	v := validator{json: json, maxDepth: maxDepth}
+	v.skipSpace()
+	if err := v.value(); err != nil {
+		return err
+	}
+	v.skipSpace()
+	if v.i < len(v.json) {
+		return v.errorf("unexpected %q after top-level value", v.json[v.i])
+	}
+	return nil
+}
+
+// validator is a recursive descent JSON validator
+type validator struct {
+	json string
+	i    int
+
+	depth, maxDepth int
+}
+
+func (v *validator) errorf(format string, args ...interface{}) error {
+	return newJSONError(v.json, v.i, fmt.Sprintf(format, args...))
+}
+
+// unexpected reports the character at the current position, or the end of input
+func (v *validator) unexpected(expected string) error {
+	if v.i >= len(v.json) {
+		return v.errorf("unexpected end of input, expected %s", expected)
+	}
+	return v.errorf("unexpected %q, expected %s", v.json[v.i], expected)
+}
+
+// enter records entering an object or array, checking the depth limit
+func (v *validator) enter() error {
+	v.depth++
+	if v.maxDepth > 0 && v.depth > v.maxDepth {
+		return &LimitError{Limit: LimitDepth, Max: v.maxDepth}
+	}
+	return nil
+}
+
+func (v *validator) leave() {
+	v.depth--
+}
+
+func (v *validator) skipSpace() {
+	v.i = skipWhitespaceJSON(v.json, v.i)
+}
+
+func (v *validator) value() error {
+	if v.i >= len(v.json) {
+		return v.unexpected("value")
+	}
+	switch ch := v.json[v.i]; {
+	case ch == '{':
+		return v.object()
+	case ch == '[':
+		return v.array()
+	case ch == '"':
+		return v.string()
+	case ch == '-' || (ch >= '0' && ch <= '9'):
+		return v.number()
+	case ch == 't':
+		return v.literal("true")
+	case ch == 'f':
+		return v.literal("false")
+	case ch == 'n':
+		return v.literal("null")
+	default:
+		return v.unexpected("value")
+	}
+}
+
+func (v *validator) object() error {
+	if err := v.enter(); err != nil {
+		return err
+	}
+	defer v.leave()
+	v.i++ // '{'
+	v.skipSpace()
+	if v.i < len(v.json) && v.json[v.i] == '}' {
+		v.i++
+		return nil
+	}
+	for {
+		if v.i >= len(v.json) || v.json[v.i] != '"' {
+			return v.unexpected("string for member name")
+		}
+		if err := v.string(); err != nil {
+			return err
+		}
+		v.skipSpace()
+		if v.i >= len(v.json) || v.json[v.i] != ':' {
+			return v.unexpected("':' after member name")
+		}
+		v.i++
+		v.skipSpace()
+		if err := v.value(); err != nil {
+			return err
+		}
+		v.skipSpace()
+		if v.i >= len(v.json) {
+			return v.unexpected("',' or '}'")
+		}
+		switch v.json[v.i] {
+		case ',':
+			v.i++
+			v.skipSpace()
+		case '}':
+			v.i++
+			return nil
+		default:
+			return v.unexpected("',' or '}'")
+		}
+	}
+}
+
+func (v *validator) array() error {
+	if err := v.enter(); err != nil {
+		return err
+	}
+	defer v.leave()
+	v.i++ // '['
+	v.skipSpace()
+	if v.i < len(v.json) && v.json[v.i] == ']' {
+		v.i++
+		return nil
+	}
+	for {
+		if err := v.value(); err != nil {
+			return err
+		}
+		v.skipSpace()
+		if v.i >= len(v.json) {
+			return v.unexpected("',' or ']'")
+		}
+		switch v.json[v.i] {
+		case ',':
+			v.i++
+			v.skipSpace()
+		case ']':
+			v.i++
+			return nil
+		default:
+			return v.unexpected("',' or ']'")
+		}
+	}
+}
+
+func (v *validator) string() error {
+	v.i++ // '"'
+	for v.i < len(v.json) {
+		ch := v.json[v.i]
+		switch {
+		case ch == '"':
+			v.i++
+			return nil
+		case ch == '\\':
+			v.i++
+			if v.i >= len(v.json) {
+				return v.unexpected("escape character")
+			}
+			switch v.json[v.i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				v.i++
+			case 'u':
+				v.i++
+				for k := 0; k < 4; k++ {
+					if v.i >= len(v.json) || !isHexDigit(v.json[v.i]) {
+						return v.unexpected("hexadecimal digit in \\u escape")
+					}
+					v.i++
+				}
+			default:
+				return v.unexpected("escape character")
+			}
+		case ch < ' ':
+			return v.errorf("unexpected control character %q in string", ch)
+		case ch < utf8.RuneSelf:
+			v.i++
+		default:
+			r, size := utf8.DecodeRuneInString(v.json[v.i:])
+			if r == utf8.RuneError && size == 1 {
+				return v.errorf("invalid UTF-8 in string")
+			}
+			v.i += size
+		}
+	}
+	return v.unexpected("'\"' to end string")
+}
+
+func (v *validator) number() error {
+	if v.json[v.i] == '-' {
+		v.i++
+	}
+	if v.i < len(v.json) && v.json[v.i] == '0' {
+		v.i++
+	} else if err := v.digits(); err != nil {
+		return err
+	}
+	if v.i < len(v.json) && v.json[v.i] == '.' {
+		v.i++
+		if err := v.digits(); err != nil {
+			return err
+		}
+	}
+	if v.i < len(v.json) && (v.json[v.i] == 'e' || v.json[v.i] == 'E') {
+		v.i++
+		if v.i < len(v.json) && (v.json[v.i] == '+' || v.json[v.i] == '-') {
+			v.i++
+		}
+		if err := v.digits(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// digits consumes one or more decimal digits
+func (v *validator) digits() error {
+	start := v.i
+	for v.i < len(v.json) && v.json[v.i] >= '0' && v.json[v.i] <= '9' {
+		v.i++
+	}
+	if v.i == start {
+		return v.unexpected("digit")
+	}
+	return nil
+}
+
+func (v *validator) literal(lit string) error {
+	for k := 0; k < len(lit); k++ {
+		if v.i >= len(v.json) || v.json[v.i] != lit[k] {
+			return v.unexpected(fmt.Sprintf("%q", lit))
+		}
+		v.i++
+	}
+	return nil
+}
+
+func isHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
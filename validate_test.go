@@ -0,0 +1,118 @@
+package jsonpath
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{"对象", `{"a": [1, 2.5, -3e10, true, false, null, "s"]}`, false},
+		{"空对象", `{}`, false},
+		{"空数组", ` [ ] `, false},
+		{"标量", `"x"`, false},
+		{"零", `0`, false},
+		{"转义", `"\" \\ \/ \b \f \n \r \t é"`, false},
+		{"Unicode", `"世界"`, false},
+		{"空文档", ``, true},
+		{"仅空白", " \n ", true},
+		{"未闭合对象", `{"a": 1`, true},
+		{"未闭合数组", `[1, 2`, true},
+		{"未闭合字符串", `"abc`, true},
+		{"尾随逗号", `[1, 2,]`, true},
+		{"对象尾随逗号", `{"a": 1,}`, true},
+		{"缺少冒号", `{"a" 1}`, true},
+		{"单引号", `{'a': 1}`, true},
+		{"NaN", `NaN`, true},
+		{"Infinity", `[Infinity]`, true},
+		{"负无穷", `-Infinity`, true},
+		{"前导零", `01`, true},
+		{"正号", `+1`, true},
+		{"小数点后无数字", `1.`, true},
+		{"指数无数字", `1e`, true},
+		{"非法转义", `"\x"`, true},
+		{"短 Unicode 转义", `"\u12"`, true},
+		{"控制字符", "\"a\tb\"", true},
+		{"非法 UTF-8", "\"\xff\"", true},
+		{"多余内容", `{} {}`, true},
+		{"拼写错误", `tru`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.json)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate(%q) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			}
+			if Valid(tt.json) != !tt.wantErr {
+				t.Errorf("Valid(%q) = %v, want %v", tt.json, !tt.wantErr, tt.wantErr)
+			}
+			// encoding/json does not check UTF-8, RFC 8259 requires it
+			if want := stdjson.Valid([]byte(tt.json)); want == tt.wantErr && tt.name != "非法 UTF-8" {
+				t.Errorf("Validate(%q) disagrees with encoding/json", tt.json)
+			}
+		})
+	}
+}
+
+func TestValidateErrorPosition(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantOffset int
+		wantLine   int
+		wantColumn int
+		wantMsg    string
+	}{
+		{"首行", `{"a" 1}`, 5, 1, 6, `unexpected '1', expected ':' after member name`},
+		{"第三行", "{\n  \"a\": 1,\n  \"b\": x\n}", 19, 3, 8, `unexpected 'x', expected value`},
+		{"多字节字符", "[\"世界\", ]", 11, 1, 8, `unexpected ']', expected value`},
+		{"输入结束", `[1`, 2, 1, 3, `unexpected end of input, expected ',' or ']'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.json)
+			var jsonErr *JSONError
+			if !errors.As(err, &jsonErr) {
+				t.Fatalf("Validate() error = %v, want *JSONError", err)
+			}
+			if jsonErr.Offset != tt.wantOffset || jsonErr.Line != tt.wantLine || jsonErr.Column != tt.wantColumn || jsonErr.Msg != tt.wantMsg {
+				t.Errorf("Validate() = %+v, want offset %d line %d column %d msg %q",
+					jsonErr, tt.wantOffset, tt.wantLine, tt.wantColumn, tt.wantMsg)
+			}
+			if !errors.Is(err, ErrInvalidJSON) {
+				t.Errorf("Validate() error is not ErrInvalidJSON")
+			}
+		})
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	lenient := MustCompile("$[*]")
+	strict, err := CompileWithOptions("$[*]", &Options{Strict: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, json := range []string{`[1, NaN]`, `[1, 2`, `[1] x`} {
+		if got := lenient.GetMany(json); len(got) == 0 {
+			t.Errorf("lenient GetMany(%q) returned no results", json)
+		}
+		if got := strict.GetMany(json); len(got) != 0 {
+			t.Errorf("strict GetMany(%q) = %v, want no results", json, got)
+		}
+		if _, err := strict.QueryAll(json); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("strict QueryAll(%q) error = %v, want ErrInvalidJSON", json, err)
+		}
+	}
+
+	if got, err := strict.QueryAll(`[1, 2]`); err != nil || len(got) != 2 {
+		t.Errorf("strict QueryAll() = %v, %v", got, err)
+	}
+}
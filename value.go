@@ -0,0 +1,360 @@
+package jsonpath
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Kind is the JSON kind of a Value
+type Kind int
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+// String returns the name of the kind
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// Value is a node of a document tree the evaluator can walk. The package
+// implements it for JSON text and for Go values; implementing it for another
+// tree, such as a YAML document, lets queries run on that tree directly.
+//
+// Methods that do not apply to the kind of the value return zero values.
+type Value interface {
+	Kind() Kind
+	// Member returns the value of the object member name
+	Member(name string) (Value, bool)
+	// Index returns the array element at the non-negative index i
+	Index(i int) (Value, bool)
+	// Len returns the number of array elements or object members
+	Len() int
+	// Each calls fn for every array element or object member in order,
+	// stopping when fn returns false. Steps of array elements hold their
+	// index, steps of object members their name.
+	Each(fn func(step LocationStep, v Value) bool)
+	Bool() bool
+	Number() float64
+	Str() string
+}
+
+// EvaluateTree evaluates the query against a document tree and returns the
+// selected values
+func (e *Evaluator) EvaluateTree(root Value) ([]Value, error) {
+	if root == nil {
+		return nil, nil
+	}
+	ev := newEvaluation(root, &e.opts)
+	nodes := ev.evaluate(e.segments)
+	if len(nodes) == 0 {
+		return nil, ev.err
+	}
+	values := make([]Value, len(nodes))
+	for i, n := range nodes {
+		values[i] = n.value
+	}
+	return values, ev.err
+}
+
+// QueryTree evaluates path against a document tree, see Evaluator.EvaluateTree
+func QueryTree(root Value, path string) ([]Value, error) {
+	p, err := compileCached(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.QueryTree(root)
+}
+
+// QueryTree evaluates the path against a document tree, see Evaluator.EvaluateTree
+func (p *Path) QueryTree(root Value) ([]Value, error) {
+	return p.eval.EvaluateTree(root)
+}
+
+// jsonValue is a Value backed by JSON text
+type jsonValue struct {
+	r Result
+}
+
+// newJSONValue wraps r, returning nil if r does not exist
+func newJSONValue(r Result) Value {
+	if !r.Exists() {
+		return nil
+	}
+	return jsonValue{r: r}
+}
+
+func (v jsonValue) Kind() Kind {
+	switch v.r.Type {
+	case JSONTypeTrue, JSONTypeFalse:
+		return KindBool
+	case JSONTypeNumber:
+		return KindNumber
+	case JSONTypeString:
+		return KindString
+	case JSONTypeJSON:
+		if v.r.IsArray() {
+			return KindArray
+		}
+		if v.r.IsObject() {
+			return KindObject
+		}
+	}
+	return KindNull
+}
+
+func (v jsonValue) Member(name string) (Value, bool) {
+	if !v.r.IsObject() {
+		return nil, false
+	}
+	if m, ok := v.r.member(name); ok {
+		return jsonValue{r: m}, true
+	}
+	return nil, false
+}
+
+func (v jsonValue) Index(i int) (Value, bool) {
+	if !v.r.IsArray() {
+		return nil, false
+	}
+	if elem, ok := v.r.element(i); ok {
+		return jsonValue{r: elem}, true
+	}
+	return nil, false
+}
+
+func (v jsonValue) Len() int {
+	if v.r.IsArray() {
+		return v.r.elementCount()
+	}
+	if v.r.IsObject() {
+		return len(v.r.MapKVList())
+	}
+	return 0
+}
+
+// eachInRange calls fn with the elements from start up to end (exclusive, or
+// the end of the array when end is negative) every step positions. A negative
+// step walks down from start to end (exclusive, or the first element when end
+// is negative). Elements that are not selected are skipped without being parsed.
+func (v jsonValue) eachInRange(start, end, step int, fn func(i int, elem Value) bool) bool {
+	if step < 0 {
+		return v.eachInRangeBackward(start, end, step, fn)
+	}
+	cont := true
+	v.r.forEachElementOffset(func(i, offset int) bool {
+		if end >= 0 && i >= end {
+			return false
+		}
+		if i < start || (i-start)%step != 0 {
+			return true
+		}
+		elem, ok := v.r.elementAt(offset)
+		if !ok {
+			return false
+		}
+		cont = fn(i, jsonValue{r: elem})
+		return cont
+	})
+	return cont
+}
+
+// eachInRangeBackward is eachInRange for a negative step. The offsets of the
+// elements up to start are collected in one scan and walked backwards.
+func (v jsonValue) eachInRangeBackward(start, end, step int, fn func(i int, elem Value) bool) bool {
+	var offsets []int
+	v.r.forEachElementOffset(func(i, offset int) bool {
+		offsets = append(offsets, offset)
+		return i < start
+	})
+	for i := start; i > end && i >= 0; i += step {
+		if i >= len(offsets) {
+			continue
+		}
+		elem, ok := v.r.elementAt(offsets[i])
+		if !ok {
+			return true
+		}
+		if !fn(i, jsonValue{r: elem}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v jsonValue) Each(fn func(step LocationStep, v Value) bool) {
+	i := 0
+	v.r.forEachElement(func(elem Result) bool {
+		cont := fn(indexStep(i), jsonValue{r: elem})
+		i++
+		return cont
+	})
+	v.r.forEachMember(func(key string, value Result) bool {
+		return fn(nameStep(key), jsonValue{r: value})
+	})
+}
+
+func (v jsonValue) Bool() bool      { return v.r.Type == JSONTypeTrue }
+func (v jsonValue) Number() float64 { return v.r.Num }
+func (v jsonValue) Str() string     { return v.r.Str }
+
+// valueResult returns v as a Result. Values not backed by JSON text are
+// encoded to JSON first.
+func valueResult(v Value) Result {
+	switch v := v.(type) {
+	case nil:
+		return Result{}
+	case jsonValue:
+		return v.r
+	case docValue:
+		return v.node().r
+	case *goValue:
+		return v.result()
+	}
+	var buf strings.Builder
+	writeValue(&buf, v)
+	return parseValue(buf.String())
+}
+
+// writeValue encodes v as JSON
+func writeValue(buf *strings.Builder, v Value) {
+	switch v.Kind() {
+	case KindBool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case KindNumber:
+		f := v.Number()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			buf.WriteString("null")
+		} else {
+			buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case KindString:
+		buf.WriteString(quoteString(v.Str()))
+	case KindArray:
+		buf.WriteByte('[')
+		v.Each(func(step LocationStep, elem Value) bool {
+			if step.Index > 0 {
+				buf.WriteByte(',')
+			}
+			writeValue(buf, elem)
+			return true
+		})
+		buf.WriteByte(']')
+	case KindObject:
+		buf.WriteByte('{')
+		first := true
+		v.Each(func(step LocationStep, member Value) bool {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			buf.WriteString(quoteString(step.Name))
+			buf.WriteByte(':')
+			writeValue(buf, member)
+			return true
+		})
+		buf.WriteByte('}')
+	default:
+		buf.WriteString("null")
+	}
+}
+
+// valuesEqual reports whether a and b are equal as defined by RFC 9535 §2.3.5.2.2:
+// arrays are equal when their elements are pairwise equal, and objects when
+// they have the same member names with equal values.
+//
+// The elements and members compared count as visited nodes at their depth
+// below the operands, so the limits of the evaluation bound the comparison.
+// Arrays and objects are not equal once the evaluation has stopped.
+func (e *evaluation) valuesEqual(a, b Value, depth int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	k := a.Kind()
+	if k != b.Kind() {
+		return false
+	}
+
+	switch k {
+	case KindNull:
+		return true
+	case KindBool:
+		return a.Bool() == b.Bool()
+	case KindNumber:
+		return a.Number() == b.Number()
+	case KindString:
+		return a.Str() == b.Str()
+	case KindArray:
+		// Collect the elements of b once and walk them along with those of a
+		var elems []Value
+		b.Each(func(_ LocationStep, bv Value) bool {
+			elems = append(elems, bv)
+			return true
+		})
+		n, equal := 0, true
+		a.Each(func(_ LocationStep, av Value) bool {
+			equal = n < len(elems) && e.visit(depth+1) && e.valuesEqual(av, elems[n], depth+1)
+			n++
+			return equal
+		})
+		return equal && n == len(elems)
+	case KindObject:
+		// Index the members of b once; the last member of a name wins, as with Member
+		members := make(map[string]Value)
+		count := 0
+		b.Each(func(step LocationStep, bv Value) bool {
+			members[step.Name] = bv
+			count++
+			return true
+		})
+		n, equal := 0, true
+		a.Each(func(step LocationStep, av Value) bool {
+			bv, ok := members[step.Name]
+			equal = ok && e.visit(depth+1) && e.valuesEqual(av, bv, depth+1)
+			n++
+			return equal
+		})
+		return equal && n == count
+	}
+	return false
+}
+
+// valueLess reports whether a < b; only numbers and strings are ordered
+func valueLess(a, b Value) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	k := a.Kind()
+	if k != b.Kind() {
+		return false
+	}
+
+	switch k {
+	case KindNumber:
+		return a.Number() < b.Number()
+	case KindString:
+		return a.Str() < b.Str()
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package jsonpath
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// treeValue is a minimal Value implementation standing in for a foreign document model
+type treeValue struct {
+	v interface{}
+}
+
+func (t treeValue) Kind() Kind {
+	switch t.v.(type) {
+	case bool:
+		return KindBool
+	case float64:
+		return KindNumber
+	case string:
+		return KindString
+	case []treeValue:
+		return KindArray
+	case map[string]treeValue:
+		return KindObject
+	}
+	return KindNull
+}
+
+func (t treeValue) Member(name string) (Value, bool) {
+	m, ok := t.v.(map[string]treeValue)[name]
+	return m, ok
+}
+
+func (t treeValue) Index(i int) (Value, bool) {
+	arr, _ := t.v.([]treeValue)
+	if i < 0 || i >= len(arr) {
+		return nil, false
+	}
+	return arr[i], true
+}
+
+func (t treeValue) Len() int {
+	switch v := t.v.(type) {
+	case []treeValue:
+		return len(v)
+	case map[string]treeValue:
+		return len(v)
+	}
+	return 0
+}
+
+func (t treeValue) Each(fn func(step LocationStep, v Value) bool) {
+	switch v := t.v.(type) {
+	case []treeValue:
+		for i, elem := range v {
+			if !fn(indexStep(i), elem) {
+				return
+			}
+		}
+	case map[string]treeValue:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if !fn(nameStep(k), v[k]) {
+				return
+			}
+		}
+	}
+}
+
+func (t treeValue) Bool() bool      { b, _ := t.v.(bool); return b }
+func (t treeValue) Number() float64 { f, _ := t.v.(float64); return f }
+func (t treeValue) Str() string     { s, _ := t.v.(string); return s }
+
+func TestQueryTree(t *testing.T) {
+	root := treeValue{map[string]treeValue{
+		"books": {[]treeValue{
+			{map[string]treeValue{"title": {"A"}, "price": {8.0}, "tags": {[]treeValue{{"x"}}}}},
+			{map[string]treeValue{"title": {"B"}, "price": {20.0}, "tags": {[]treeValue{}}}},
+		}},
+		"max":  {10.0},
+		"none": {nil},
+	}}
+
+	tests := []struct {
+		name string
+		path string
+		want []interface{}
+	}{
+		{"成员", "$.books[0].title", []interface{}{"A"}},
+		{"负索引", "$.books[-1].title", []interface{}{"B"}},
+		{"切片", "$.books[::-1].title", []interface{}{"B", "A"}},
+		{"后代", "$..title", []interface{}{"A", "B"}},
+		{"过滤", "$.books[?@.price < $.max].title", []interface{}{"A"}},
+		{"函数", "$.books[?count(@.tags[*]) == 0].title", []interface{}{"B"}},
+		{"函数取值", `$.books[?match(@.title, "[AB]")].price`, []interface{}{8.0, 20.0}},
+		{"null", "$[?@ == null]", []interface{}{nil}},
+		{"无匹配", "$.missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := QueryTree(root, tt.path)
+			if err != nil {
+				t.Fatalf("QueryTree() error = %v", err)
+			}
+			var got []interface{}
+			for _, v := range values {
+				got = append(got, v.(treeValue).v)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueryTree() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueResult(t *testing.T) {
+	v := treeValue{map[string]treeValue{"a": {[]treeValue{{1.5}, {true}, {nil}, {"x\""}}}}}
+	if got, want := valueResult(v).Raw, `{"a":[1.5,true,null,"x\""]}`; got != want {
+		t.Errorf("valueResult() = %s, want %s", got, want)
+	}
+}